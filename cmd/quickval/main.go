@@ -48,6 +48,46 @@ var app = &cli.App{
 			Value: "",
 			Usage: "ticker to base our valuation on",
 		},
+		&cli.StringFlag{
+			Name:  "country-rates",
+			Value: "",
+			Usage: "JSON file of risk-free rates and country risk premiums, overriding the built-in defaults",
+		},
+		&cli.StringFlag{
+			Name:  "cv-peers",
+			Value: "",
+			Usage: "file of peer tickers, or \"country\" for a sample of the country's tickers, to scale the CV Weighted WACC by the median peer CV",
+		},
+		&cli.IntFlag{
+			Name:  "cv-sample",
+			Value: 50,
+			Usage: "number of the country's tickers to sample with --cv-peers country (each one uses API quota)",
+		},
+		&cli.Float64Flag{
+			Name:  "max-terminal-share",
+			Value: 0.75,
+			Usage: "warn when the terminal value is above this share of the total value of a DCF",
+		},
+		&cli.Float64Flag{
+			Name:  "unlevered-beta",
+			Value: 0.00,
+			Usage: "unlevered (e.g. industry) beta to relever in the WACC (defaults to the company's unlevered beta)",
+		},
+		&cli.Float64Flag{
+			Name:  "target-debt-to-equity",
+			Value: 0.00,
+			Usage: "target debt-to-equity ratio for the WACC (defaults to the current ratio)",
+		},
+		&cli.Float64Flag{
+			Name:  "cost-of-debt",
+			Value: 0.00,
+			Usage: "pre-tax cost of debt for the WACC (defaults to interest expense / total debt)",
+		},
+		&cli.BoolFlag{
+			Name:  "synthetic-rating",
+			Value: false,
+			Usage: "estimate the cost of debt from a synthetic rating based on interest coverage",
+		},
 	},
 	Before: func(cCtx *cli.Context) error {
 		if bashCompletionsMode {
@@ -65,6 +105,18 @@ var app = &cli.App{
 	Commands: []*cli.Command{
 		growthExitCommand,
 		twoStageCommand,
+		threeStageCommand,
+		storyCommand,
+		turnaroundCommand,
 		dividendDiscountCommand,
+		payoutDividendCommand,
+		shareholderYieldCommand,
+		hModelCommand,
+		residualIncomeCommand,
+		epvCommand,
+		reverseDCFCommand,
+		monteCarloCommand,
+		scenariosCommand,
+		quickChecksCommand,
 	},
 }
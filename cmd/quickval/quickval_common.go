@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"io/fs"
+	"math"
+	"math/rand"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -15,6 +17,7 @@ import (
 
 	"github.com/manifoldco/promptui"
 	"github.com/shanehull/quickval/internal/calc"
+	"github.com/shanehull/quickval/internal/market"
 	"github.com/shanehull/quickval/internal/output"
 	"github.com/shanehull/quickval/internal/quickfs"
 	"github.com/urfave/cli/v2"
@@ -27,27 +30,97 @@ var (
 )
 
 var (
-	rfrPromptInfo       = "Enter a Risk-Rree Rate (e.g a 10-year average of a 10-year treasury bond yield)."
-	erpPromptInfo       = "Enter an ERP (Equity Risk Premium)."
+	rfrPromptInfo       = "Enter a Risk-Rree Rate (e.g a 10-year government bond yield), or accept the default for the country."
+	erpPromptInfo       = "Enter an ERP (Equity Risk Premium), or accept the default (mature market ERP + country risk premium)."
 	discPromptInfo      = "Enter an explicit discount rate."
-	growthPromptInfo    = "Enter a reasonable growth rate, or accept the default (derived from a CAGR of the FCF or dividend history)."
+	growthPromptInfo    = "Enter a reasonable growth rate, or accept the default (derived from the chosen growth method)."
 	fcfPromptInfo       = "Enter a current FCF (e.g. a normalised figure) or accept the most recent reported figure."
 	dividendsPromptInfo = "Enter a current Cash Paid for Dividends value (e.g. a normalised figure) or accept the most recent reported figure."
 	exitPromptInfo      = "Enter an exit multiple, or accept the current P/FCF (rounded down)."
 	perpetualGrowthInfo = "Enter a growth rate for the perpetual/terminal growth stage."
 	fyHistoryPromptInfo = "Enter a FY history to retrieve for financial reports."
+	transitionYearsInfo = "Enter the number of years over which growth fades to the perpetual growth rate."
+	modelPromptInfo     = "Choose the valuation model to use."
+	halfLifePromptInfo  = "Enter the half-life (in years) of the period where dividend growth declines to the long-term rate."
+	bookValuePromptInfo = "Enter a current book value of equity or accept the most recent reported figure."
+	roePromptInfo       = "Enter a sustainable ROE (Return on Equity), or accept the default (the average of the ROE history)."
+	ebitMarginInfo      = "Enter a normalized EBIT margin, or accept the default (the average of the EBIT margin history)."
+	maintCapexInfo      = "Enter the capex required to maintain current earnings, or accept the default (the average D&A)."
+	growthMethodInfo    = "Compare the growth rate from each method, e.g. to spot when the CAGR is skewed by its endpoints, and choose one to tweak."
+	normalizePromptInfo = "Choose how to normalize the current figure. Each option shows the value it produces, which you can then tweak."
+	revenuePromptInfo   = "Enter a current revenue (e.g. a normalised figure) or accept the most recent reported figure."
+	marginPromptInfo    = "Enter the current operating (EBIT) margin, or accept the most recent reported margin."
+	targetMarginInfo    = "Enter the operating margin the company converges to, or accept the default (the average of the margin history)."
+	salesToCapitalInfo  = "Enter the revenue added per unit of capital reinvested, or accept the default (revenue / invested capital over the history)."
+	turnaroundYearInfo  = "Enter the first projected year of positive FCF, the cash burn narrows linearly until then."
+	targetFCFMarginInfo = "Enter the FCF margin the company reaches in the final projected year, applied to the projected revenue."
+	failurePromptInfo   = "Enter the probability that the company fails (e.g. runs out of funding) before it turns around."
+	earningsPromptInfo  = "Enter a current net income (e.g. a normalised figure) or accept the most recent reported figure."
+	currentPayoutInfo   = "Enter the share of earnings paid as dividends during high growth, or accept the default (dividends / net income over the history)."
+	maturePayoutInfo    = "Enter the share of earnings paid as dividends once mature, or accept the default (1 - perpetual growth rate / average ROE)."
+	payoutPromptInfo    = "Enter a current shareholder payout (e.g. a normalised figure, as buybacks are lumpy) or accept the most recent reported figure."
+	epsPromptInfo       = "Enter a current EPS (e.g. a normalised figure) or accept the most recent net income per share."
+	scenarioPromptInfo  = "Enter the inputs of each scenario. The probabilities must sum to 1, and a FCF margin of 0 starts from the current FCF."
 )
 
 var (
-	defaultRFR           = 0.042
-	defaultPerpetualRate = 0.02
-	defaultERP           = 0.05
+	defaultPerpetualRate   = 0.02
+	defaultTransitionYears = 5
+	defaultHalfLife        = 5.0
+	minImpliedReturn       = -0.99
+	trimmedMeanShare       = 0.2
+	scenarioGrowthSpread   = 0.05
+	defaultTurnaroundYear  = 3
+	defaultTargetMargin    = 0.10
+	defaultFailureProb     = 0.10
 )
 
+var (
+	sensitivitySteps        = 2
+	sensitivityMultipleStep = 2.0
+)
+
+var (
+	discountRateOpts   = []string{"WACC", "Cost of Equity", "CV Weighted WACC", "Custom Input"}
+	enterpriseRateOpts = []string{"WACC", "CV Weighted WACC", "Custom Input"}
+	equityRateOpts     = []string{"Cost of Equity", "Custom Input"}
+)
+
+// doCommonSetup retrieves the FY history and data for the ticker, and the discount rate from any of the discount rate options.
 func doCommonSetup(
 	cCtx *cli.Context,
 	writer *output.Writer,
 	opts ...quickfs.ConfigOption,
+) (quickfs.Data, int, float64, error) {
+	return doSetup(cCtx, writer, discountRateOpts, opts...)
+}
+
+// doEnterpriseSetup is doCommonSetup for enterprise models, e.g. the EPV and unlevered FCF, which must be discounted at
+// a WACC rather than the cost of equity. A custom input is still offered, for an explicit WACC.
+func doEnterpriseSetup(
+	cCtx *cli.Context,
+	writer *output.Writer,
+	opts ...quickfs.ConfigOption,
+) (quickfs.Data, int, float64, error) {
+	return doSetup(cCtx, writer, enterpriseRateOpts, opts...)
+}
+
+// doEquitySetup is doCommonSetup for equity models, e.g. residual income and dividends, which must be discounted at the
+// cost of equity rather than a WACC. A custom input is still offered, for an explicit cost of equity.
+func doEquitySetup(
+	cCtx *cli.Context,
+	writer *output.Writer,
+	opts ...quickfs.ConfigOption,
+) (quickfs.Data, int, float64, error) {
+	return doSetup(cCtx, writer, equityRateOpts, opts...)
+}
+
+// doSetup retrieves the FY history and data for the ticker, and the discount rate from one of the given options.
+func doSetup(
+	cCtx *cli.Context,
+	writer *output.Writer,
+	rateOpts []string,
+	opts ...quickfs.ConfigOption,
 ) (quickfs.Data, int, float64, error) {
 	var (
 		data              quickfs.Data
@@ -67,7 +140,13 @@ func doCommonSetup(
 	discountRate := cCtx.Float64("discount-rate")
 	var discountRateOpt string
 	if discountRate == 0.00 {
-		discountRateOpt = selectDiscountRateOpt()
+		var rates market.Rates
+		rates, err = countryRates(cCtx)
+		if err != nil {
+			return data, fyHistory, discountRate, err
+		}
+
+		discountRateOpt = selectDiscountRateOpt(rateOpts)
 
 		switch discountRateOpt {
 		case "WACC":
@@ -75,7 +154,7 @@ func doCommonSetup(
 			if equityRiskPremium == 0.0 {
 				equityRiskPremium, err = promptFloat(
 					"Equity Risk Premium",
-					defaultERP,
+					rates.EquityRiskPremium(),
 					erpPromptInfo,
 				)
 				if err != nil {
@@ -85,7 +164,7 @@ func doCommonSetup(
 
 			riskFreeRate = cCtx.Float64("risk-free")
 			if riskFreeRate == 0.0 {
-				riskFreeRate, err = promptFloat("Risk-Free Rate", defaultRFR, rfrPromptInfo)
+				riskFreeRate, err = promptFloat("Risk-Free Rate", rates.RiskFreeRate, rfrPromptInfo)
 				if err != nil {
 					return data, fyHistory, discountRate, err
 				}
@@ -95,6 +174,9 @@ func doCommonSetup(
 				quickfs.WithAPIKey(apiKey),
 				quickfs.WithFYHistory(fyHistory),
 				quickfs.WithBeta(),
+				quickfs.WithEBIT(),
+				quickfs.WithInterestExpense(),
+				quickfs.WithBalanceSheet(),
 			)
 
 			qfs := quickfs.NewQuickFS(
@@ -106,22 +188,87 @@ func doCommonSetup(
 				return data, fyHistory, discountRate, fmt.Errorf("error getting data: %s", err)
 			}
 
+			debtToEquity := cCtx.Float64("target-debt-to-equity")
+			if debtToEquity == 0.00 {
+				debtToEquity = data.DebtToEquity
+			}
+
+			unleveredBeta := cCtx.Float64("unlevered-beta")
+			if unleveredBeta == 0.00 {
+				unleveredBeta = calc.UnleveredBeta(data.Beta, data.DebtToEquity, data.TaxRate)
+			}
+
+			costOfDebt, costOfDebtSource := estimateCostOfDebt(cCtx, &data, riskFreeRate)
+
 			wacc := calc.WACC(
-				data.Beta,
-				data.DebtToEquity,
+				unleveredBeta,
+				debtToEquity,
 				data.TaxRate,
 				equityRiskPremium,
 				riskFreeRate,
+				costOfDebt,
 			)
 
-			discountRate = wacc
+			discountRate = wacc.WACC
 
 			writer.Data(&data)
-			writer.WACC(discountRate, equityRiskPremium, riskFreeRate, &data)
+			writer.WACC(
+				wacc,
+				equityRiskPremium,
+				riskFreeRate,
+				debtToEquity,
+				"Relevered Beta",
+				costOfDebtSource,
+				&data,
+			)
+		case "Cost of Equity":
+			equityRiskPremium = cCtx.Float64("risk-premium")
+			if equityRiskPremium == 0.0 {
+				equityRiskPremium, err = promptFloat(
+					"Equity Risk Premium",
+					rates.EquityRiskPremium(),
+					erpPromptInfo,
+				)
+				if err != nil {
+					return data, fyHistory, discountRate, err
+				}
+			}
+
+			riskFreeRate = cCtx.Float64("risk-free")
+			if riskFreeRate == 0.0 {
+				riskFreeRate, err = promptFloat("Risk-Free Rate", rates.RiskFreeRate, rfrPromptInfo)
+				if err != nil {
+					return data, fyHistory, discountRate, err
+				}
+			}
+
+			mergedOpts := append(opts,
+				quickfs.WithAPIKey(apiKey),
+				quickfs.WithFYHistory(fyHistory),
+				quickfs.WithBeta(),
+			)
+
+			qfs := quickfs.NewQuickFS(
+				mergedOpts...,
+			)
+
+			data, err = qfs.GetData(ticker, country)
+			if err != nil {
+				return data, fyHistory, discountRate, fmt.Errorf("error getting data: %s", err)
+			}
+
+			discountRate = calc.CostOfEquity(data.Beta, equityRiskPremium, riskFreeRate)
+
+			writer.Data(&data)
+			writer.CostOfEquity(discountRate, equityRiskPremium, riskFreeRate, &data)
 		case "CV Weighted WACC":
 			equityRiskPremium = cCtx.Float64("risk-premium")
 			if equityRiskPremium == 0.0 {
-				equityRiskPremium, err = promptFloat("Equity Risk Premium", 0.05, erpPromptInfo)
+				equityRiskPremium, err = promptFloat(
+					"Equity Risk Premium",
+					rates.EquityRiskPremium(),
+					erpPromptInfo,
+				)
 				if err != nil {
 					return data, fyHistory, discountRate, err
 				}
@@ -129,7 +276,7 @@ func doCommonSetup(
 
 			riskFreeRate = cCtx.Float64("risk-free")
 			if riskFreeRate == 0.0 {
-				riskFreeRate, err = promptFloat("Risk Free Rate", defaultRFR, rfrPromptInfo)
+				riskFreeRate, err = promptFloat("Risk Free Rate", rates.RiskFreeRate, rfrPromptInfo)
 				if err != nil {
 					return data, fyHistory, discountRate, err
 				}
@@ -138,6 +285,9 @@ func doCommonSetup(
 			mergedOpts := append(opts,
 				quickfs.WithAPIKey(apiKey),
 				quickfs.WithFYHistory(fyHistory),
+				quickfs.WithEBIT(),
+				quickfs.WithInterestExpense(),
+				quickfs.WithBalanceSheet(),
 			)
 
 			qfs := quickfs.NewQuickFS(
@@ -149,18 +299,48 @@ func doCommonSetup(
 				return data, 0, 0, fmt.Errorf("error getting data: %s", err)
 			}
 
-			wacc := calc.FCFCVWeightedWACC(
+			debtToEquity := cCtx.Float64("target-debt-to-equity")
+			if debtToEquity == 0.00 {
+				debtToEquity = data.DebtToEquity
+			}
+
+			costOfDebt, costOfDebtSource := estimateCostOfDebt(cCtx, &data, riskFreeRate)
+
+			cvs, err := peerCVs(cCtx, fyHistory)
+			if err != nil {
+				return data, 0, 0, err
+			}
+
+			wacc, err := calc.FCFCVWeightedWACC(
 				data.FCFHistory,
-				data.DebtToEquity,
+				cvs,
+				debtToEquity,
 				data.TaxRate,
 				equityRiskPremium,
 				riskFreeRate,
+				costOfDebt,
 			)
+			if err != nil {
+				return data, 0, 0, err
+			}
+
+			discountRate = wacc.WACC
 
-			discountRate = wacc
+			riskLabel := "FCF CV"
+			if len(cvs) > 0 {
+				riskLabel = fmt.Sprintf("Relative FCF CV (%d Peers)", len(cvs))
+			}
 
 			writer.Data(&data)
-			writer.WACC(discountRate, equityRiskPremium, riskFreeRate, &data)
+			writer.WACC(
+				wacc,
+				equityRiskPremium,
+				riskFreeRate,
+				debtToEquity,
+				riskLabel,
+				costOfDebtSource,
+				&data,
+			)
 		case "Custom Input":
 			discountRate, err = promptFloat("Discount Rate", 0.10, discPromptInfo)
 			if err != nil {
@@ -194,6 +374,416 @@ func doCommonSetup(
 	return data, fyHistory, discountRate, nil
 }
 
+// peerCVs returns the FCF coefficients of variance of the reference universe chosen with --cv-peers:
+// either a file of tickers (one per line, as TICKER or TICKER:COUNTRY), or "country" for a sample of the country's tickers.
+// It returns nil if no universe is chosen, and skips peers whose data can't be retrieved.
+func peerCVs(cCtx *cli.Context, fyHistory int) ([]float64, error) {
+	spec := cCtx.String("cv-peers")
+	if spec == "" {
+		return nil, nil
+	}
+
+	var peers []string
+	if spec == "country" {
+		tickers, err := fetchTickers(country)
+		if err != nil {
+			return nil, err
+		}
+
+		// a fixed seed, so that repeated runs use the same sample
+		rng := rand.New(rand.NewSource(1))
+		for _, i := range rng.Perm(len(tickers)) {
+			if len(peers) == cCtx.Int("cv-sample") {
+				break
+			}
+			peers = append(peers, tickers[i])
+		}
+	} else {
+		file, err := os.ReadFile(spec)
+		if err != nil {
+			return nil, fmt.Errorf("error reading peers: %w", err)
+		}
+
+		for _, line := range strings.Split(string(file), "\n") {
+			line = strings.TrimSpace(line)
+			if line != "" && !strings.HasPrefix(line, "#") {
+				peers = append(peers, line)
+			}
+		}
+	}
+
+	qfs := quickfs.NewQuickFS(
+		quickfs.WithAPIKey(apiKey),
+		quickfs.WithFYHistory(fyHistory),
+		quickfs.WithFCF(),
+	)
+
+	var cvs []float64
+	for _, peer := range peers {
+		peerTicker, peerCountry, found := strings.Cut(peer, ":")
+		if !found {
+			peerCountry = country
+		}
+
+		data, err := qfs.GetData(peerTicker, peerCountry)
+		if err != nil || len(data.FCFHistory) == 0 {
+			continue
+		}
+
+		cvs = append(cvs, calc.CV(data.FCFHistory))
+	}
+
+	if len(cvs) == 0 {
+		return nil, fmt.Errorf("no FCF history could be retrieved for the %d peers", len(peers))
+	}
+
+	return cvs, nil
+}
+
+// estimateCostOfDebt returns the pre-tax cost of debt and how it was estimated: from the --cost-of-debt flag, from interest expense over total debt,
+// or from a synthetic rating based on interest coverage when --synthetic-rating is set or the company reports no interest-bearing debt.
+// The data must have been retrieved with quickfs.WithEBIT(), quickfs.WithInterestExpense() and quickfs.WithBalanceSheet().
+func estimateCostOfDebt(cCtx *cli.Context, data *quickfs.Data, riskFreeRate float64) (float64, string) {
+	if costOfDebt := cCtx.Float64("cost-of-debt"); costOfDebt != 0.00 {
+		return costOfDebt, "Custom Input"
+	}
+
+	var interest, ebit int
+	if len(data.Interest) > 0 {
+		interest = data.Interest[len(data.Interest)-1]
+	}
+	if len(data.EBIT) > 0 {
+		ebit = data.EBIT[len(data.EBIT)-1]
+	}
+
+	if !cCtx.Bool("synthetic-rating") && interest > 0 {
+		if costOfDebt, err := calc.CostOfDebt(interest, data.TotalDebt); err == nil {
+			return costOfDebt, "Interest / Total Debt"
+		}
+	}
+
+	rating, spread := calc.SyntheticRating(ebit, interest)
+
+	return riskFreeRate + spread, fmt.Sprintf("Synthetic Rating (%s)", rating)
+}
+
+// unleveredBridge converts the FCF history to unlevered FCF (FCFF), and returns the equity bridge from the resulting enterprise value.
+// The data must have been retrieved with quickfs.WithInterestExpense() and quickfs.WithBalanceSheet().
+func unleveredBridge(data *quickfs.Data) (calc.EquityBridge, error) {
+	fcff, err := calc.UnleveredFCF(data.FCFHistory, data.Interest, data.TaxRate)
+	if err != nil {
+		return calc.EquityBridge{}, err
+	}
+
+	data.FCFHistory = fcff
+
+	return equityBridge(data), nil
+}
+
+// equityBridge returns the claims senior to common equity, to deduct from an enterprise value.
+// The data must have been retrieved with quickfs.WithBalanceSheet().
+func equityBridge(data *quickfs.Data) calc.EquityBridge {
+	return calc.EquityBridge{
+		NetDebt:          data.TotalDebt - data.Cash,
+		MinorityInterest: data.MinorityInterest,
+		PreferredEquity:  data.PreferredEquity,
+	}
+}
+
+// growthHistory returns the history to estimate growth from: the series restated per share when --per-share is set,
+// otherwise the series as is. The data must have been retrieved with quickfs.WithShareHistory() in per-share mode.
+func growthHistory(cCtx *cli.Context, series []int, data *quickfs.Data) ([]int, error) {
+	if !cCtx.Bool("per-share") {
+		return series, nil
+	}
+
+	return calc.ShareAdjusted(series, data.SharesHistory)
+}
+
+// discountingOpts returns the model options for the discounting convention chosen with --mid-year and --fy-end,
+// and writes the convention to the output.
+func discountingOpts(
+	cCtx *cli.Context,
+	writer *output.Writer,
+	discountRate float64,
+) ([]calc.Option, error) {
+	var opts []calc.Option
+
+	stub := 1.0
+	if fyEnd := cCtx.String("fy-end"); fyEnd != "" {
+		fyEndDate, err := time.Parse(time.DateOnly, fyEnd)
+		if err != nil {
+			return nil, fmt.Errorf("invalid fiscal year end %q, expected YYYY-MM-DD", fyEnd)
+		}
+
+		valuationDate := time.Now()
+		if date := cCtx.String("valuation-date"); date != "" {
+			valuationDate, err = time.Parse(time.DateOnly, date)
+			if err != nil {
+				return nil, fmt.Errorf("invalid valuation date %q, expected YYYY-MM-DD", date)
+			}
+		}
+
+		stub, err = calc.StubPeriod(fyEndDate, valuationDate)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, calc.WithStub(stub))
+	}
+
+	if cCtx.Bool("mid-year") {
+		opts = append(opts, calc.WithMidYear())
+	}
+
+	if value := cCtx.String("discount-curve"); value != "" {
+		rates, err := parseDiscountCurve(value)
+		if err != nil {
+			return nil, err
+		}
+
+		// the curve is relative to the discount rate, so that the implied return and sensitivities shift the whole curve
+		spreads := make([]float64, 0, len(rates))
+		for _, rate := range rates {
+			spreads = append(spreads, rate-discountRate)
+		}
+		opts = append(opts, calc.WithDiscountCurve(spreads))
+	}
+
+	writer.Discounting(cCtx.Bool("mid-year"), stub)
+
+	return opts, nil
+}
+
+// dcfSetup is the data, discount rate, equity bridge and model options shared by the FCF DCF commands.
+type dcfSetup struct {
+	data         quickfs.Data
+	fyHistory    int
+	discountRate float64
+	bridge       calc.EquityBridge
+	modelOpts    []calc.Option
+}
+
+// doDCFSetup validates the --cash-flow, --unlevered, --per-share and --share-change flags of the FCF DCF commands,
+// retrieves the data and discount rate, and returns the model options for the equity bridge, discounting convention
+// and share change. The --cash-flow basis defaults to FCF for commands without the flag.
+func doDCFSetup(cCtx *cli.Context, writer *output.Writer) (dcfSetup, error) {
+	var setup dcfSetup
+
+	cashFlow := quickfs.CashFlowFCF
+	if basis := cCtx.String("cash-flow"); basis != "" {
+		var err error
+		cashFlow, err = quickfs.ParseCashFlow(basis)
+		if err != nil {
+			return setup, cli.Exit(err.Error(), 127)
+		}
+	}
+
+	// FCFE is already levered, so it can't be bridged from an enterprise value
+	if cashFlow == quickfs.CashFlowFCFE && cCtx.Bool("unlevered") {
+		return setup, cli.Exit("the fcfe cash flow basis can't be combined with --unlevered", 127)
+	}
+
+	// per share growth already reflects changes in the share count
+	if cCtx.Bool("per-share") && cCtx.Float64("share-change") != 0.00 {
+		return setup, cli.Exit("--share-change can't be combined with --per-share", 127)
+	}
+
+	// buybacks are paid for from the FCF, so only dilution changes the value per share
+	if cCtx.Float64("share-change") < 0.00 {
+		return setup, cli.Exit(
+			"--share-change must not be negative, buybacks are already paid for from the FCF",
+			127,
+		)
+	}
+
+	qfsOpts := []quickfs.ConfigOption{
+		quickfs.WithCashFlow(cashFlow),
+		quickfs.WithRevenue(),
+		quickfs.WithEBIT(),
+		quickfs.WithROIC(),
+	}
+	if cCtx.Bool("per-share") {
+		qfsOpts = append(qfsOpts, quickfs.WithShareHistory())
+	}
+	if cCtx.Bool("unlevered") {
+		qfsOpts = append(qfsOpts, quickfs.WithInterestExpense(), quickfs.WithBalanceSheet())
+	}
+
+	// unlevered FCF values the enterprise, so it's discounted at a WACC
+	rateOpts := discountRateOpts
+	if cCtx.Bool("unlevered") {
+		rateOpts = enterpriseRateOpts
+	}
+
+	var err error
+	setup.data, setup.fyHistory, setup.discountRate, err = doSetup(cCtx, writer, rateOpts, qfsOpts...)
+	if err != nil {
+		return setup, err
+	}
+
+	if cCtx.Bool("unlevered") {
+		setup.bridge, err = unleveredBridge(&setup.data)
+		if err != nil {
+			return setup, err
+		}
+		setup.modelOpts = append(setup.modelOpts, calc.WithEquityBridge(setup.bridge))
+	}
+
+	discountOpts, err := discountingOpts(cCtx, writer, setup.discountRate)
+	if err != nil {
+		return setup, err
+	}
+	setup.modelOpts = append(setup.modelOpts, discountOpts...)
+
+	if shareChange := cCtx.Float64("share-change"); shareChange != 0.00 {
+		setup.modelOpts = append(setup.modelOpts, calc.WithShareChange(shareChange))
+	}
+
+	return setup, nil
+}
+
+// terminalWarnings checks the terminal value of a DCF against --max-terminal-share, and its growth rate against the
+// risk-free rate (--risk-free, or the country's default), as no business can outgrow the economy forever.
+func terminalWarnings(cCtx *cli.Context, breakdown calc.Breakdown) ([]string, error) {
+	var warnings []string
+
+	// the share is NaN when the total value isn't positive, and there's no share to warn about
+	maxShare := cCtx.Float64("max-terminal-share")
+	if !math.IsNaN(breakdown.TerminalShare) && breakdown.TerminalShare > maxShare {
+		warnings = append(warnings, fmt.Sprintf(
+			"Terminal value is %.0f%% of the total value (above %.0f%%)",
+			breakdown.TerminalShare*100,
+			maxShare*100,
+		))
+	}
+
+	riskFreeRate := cCtx.Float64("risk-free")
+	if riskFreeRate == 0.00 {
+		rates, err := countryRates(cCtx)
+		if err != nil {
+			return nil, err
+		}
+		riskFreeRate = rates.RiskFreeRate
+	}
+
+	if breakdown.TerminalGrowthRate > riskFreeRate {
+		warnings = append(warnings, fmt.Sprintf(
+			"Terminal growth rate of %.2f%% exceeds the risk-free rate of %.2f%%",
+			breakdown.TerminalGrowthRate*100,
+			riskFreeRate*100,
+		))
+	}
+
+	return warnings, nil
+}
+
+// parseDiscountCurve parses comma or whitespace separated discount rates, either from the value itself or from the file it names.
+func parseDiscountCurve(value string) ([]float64, error) {
+	if file, err := os.ReadFile(value); err == nil {
+		value = string(file)
+	}
+
+	fields := strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\n' || r == '\r'
+	})
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("discount curve %q has no rates", value)
+	}
+
+	rates := make([]float64, 0, len(fields))
+	for _, field := range fields {
+		rate, err := strconv.ParseFloat(field, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid discount rate %q in the discount curve", field)
+		}
+		rates = append(rates, rate)
+	}
+
+	return rates, nil
+}
+
+// writeSensitivity adds sensitivity grids of discount rate vs growth rate, and discount rate vs the terminal assumption.
+func writeSensitivity(
+	writer *output.Writer,
+	step float64,
+	discountRate, growthRate, terminal float64,
+	terminalLabel, terminalFormat string,
+	terminalStep float64,
+	valueAt func(discountRate, growthRate, terminal float64) (float64, error),
+) {
+	discountRates := calc.Steps(discountRate, step, sensitivitySteps)
+	growthRates := calc.Steps(growthRate, step, sensitivitySteps)
+	terminals := calc.Steps(terminal, terminalStep, sensitivitySteps)
+
+	writer.Sensitivity(
+		"FAIR VALUE SENSITIVITY (DISCOUNT RATE VS GROWTH RATE)",
+		"Discount Rate",
+		"Growth Rate",
+		discountRates,
+		growthRates,
+		"%.3f",
+		"%.3f",
+		calc.SensitivityMatrix(
+			discountRates,
+			growthRates,
+			func(rate, growth float64) (float64, error) {
+				return valueAt(rate, growth, terminal)
+			},
+		),
+	)
+
+	writer.Sensitivity(
+		fmt.Sprintf(
+			"FAIR VALUE SENSITIVITY (DISCOUNT RATE VS %s)",
+			strings.ToUpper(terminalLabel),
+		),
+		"Discount Rate",
+		terminalLabel,
+		discountRates,
+		terminals,
+		"%.3f",
+		terminalFormat,
+		calc.SensitivityMatrix(
+			discountRates,
+			terminals,
+			func(rate, term float64) (float64, error) {
+				return valueAt(rate, growthRate, term)
+			},
+		),
+	)
+}
+
+// countryRates returns the default risk-free rate and equity risk premium for the country,
+// from the built-in table overridden by the --country-rates file (defaults to quickval/country_rates.json in the user config directory).
+// An unknown country falls back to the US rates with a tip, or is an error when it was set with --country.
+func countryRates(cCtx *cli.Context) (market.Rates, error) {
+	path := cCtx.String("country-rates")
+	if path == "" {
+		if configDir, err := os.UserConfigDir(); err == nil {
+			path = filepath.Join(configDir, "quickval", "country_rates.json")
+		}
+	}
+
+	table, err := market.Load(path)
+	if err != nil {
+		return market.Rates{}, err
+	}
+
+	rates, ok := table.Lookup(country)
+	if !ok {
+		// an explicit country shouldn't be valued with another country's rates
+		if cCtx.IsSet("country") {
+			return market.Rates{}, fmt.Errorf(
+				"no rates for country %s, add them with --country-rates", country,
+			)
+		}
+
+		printTip(fmt.Sprintf("No rates for country %s, using the US rates.", country))
+	}
+
+	return rates, nil
+}
+
 func fetchTickers(country string) ([]string, error) {
 	cacheFilePath := filepath.Join(cacheDir, fmt.Sprintf("%s.json", country))
 
@@ -524,17 +1114,113 @@ func getFlagOrPromptFloat(
 	return value, nil
 }
 
+// growthEstimate is a growth rate produced by a growth estimation method.
+type growthEstimate struct {
+	method string
+	label  string
+	value  float64
+}
+
+// growthEstimates returns the growth rate produced by each estimation method for a series, followed by any extra estimates.
+// Methods that can't be applied, e.g. a log-linear slope through negative values, are skipped.
+func growthEstimates(series []int, extra ...growthEstimate) []growthEstimate {
+	var estimates []growthEstimate
+
+	if cagr, err := calc.CAGR(series); err == nil {
+		estimates = append(estimates, growthEstimate{method: "cagr", label: "CAGR", value: cagr})
+	}
+
+	if _, growth, err := calc.LogLinearTrend(series); err == nil {
+		estimates = append(
+			estimates,
+			growthEstimate{method: "log-linear", label: "Log-Linear Slope", value: growth},
+		)
+	}
+
+	if growth, err := calc.MedianGrowth(series); err == nil {
+		estimates = append(
+			estimates,
+			growthEstimate{method: "median-yoy", label: "Median YoY", value: growth},
+		)
+	}
+
+	return append(estimates, extra...)
+}
+
+// fundamentalGrowthEstimate returns the reinvestment rate × ROIC growth estimate,
+// if the data includes the EBIT and ROIC history it requires (see quickfs.WithEBIT() and quickfs.WithROIC()).
+// It's the growth of the total FCF from reinvested NOPAT, so it's only offered when the projected series is the reported FCF,
+// not another cash flow basis, unlevered FCF or FCF per share.
+func fundamentalGrowthEstimate(cCtx *cli.Context, data *quickfs.Data) []growthEstimate {
+	if len(data.ROIC) == 0 {
+		return nil
+	}
+
+	if cCtx.Bool("unlevered") || cCtx.Bool("per-share") {
+		return nil
+	}
+	if data.CashFlow != "" && data.CashFlow != quickfs.CashFlowFCF {
+		return nil
+	}
+
+	reinvestmentRate, err := calc.ReinvestmentRate(data.FCFHistory, data.EBIT, data.TaxRate)
+	if err != nil {
+		return nil
+	}
+
+	return []growthEstimate{{
+		method: "fundamental",
+		label:  "Reinvestment × ROIC",
+		value:  calc.FundamentalGrowth(reinvestmentRate, calc.Mean(data.ROIC)),
+	}}
+}
+
+// getFlagOrPromptGrowthRate returns the growth rate from a flag, or from the growth method flag,
+// otherwise it prompts for a growth method and then for the growth rate, defaulting to the method's value.
 func getFlagOrPromptGrowthRate(
 	cCtx *cli.Context,
 	flagName, prompt, promptInfo string,
 	series []int,
+	extra ...growthEstimate,
 ) (float64, error) {
 	value := cCtx.Float64(flagName)
-	if value == 0.00 {
-		cagr, _ := calc.CAGR(series)
-		return promptFloat(prompt, cagr, promptInfo)
+	if value != 0.00 {
+		return value, nil
 	}
-	return value, nil
+
+	estimates := growthEstimates(series, extra...)
+
+	if method := cCtx.String("growth-method"); method != "" {
+		for _, estimate := range estimates {
+			if estimate.method == method {
+				return estimate.value, nil
+			}
+		}
+		return 0, fmt.Errorf("growth method %q is not available for this history", method)
+	}
+
+	if len(estimates) == 0 {
+		return promptFloat(prompt, 0.00, promptInfo)
+	}
+
+	printTip(growthMethodInfo)
+
+	items := make([]string, 0, len(estimates))
+	for _, estimate := range estimates {
+		items = append(items, fmt.Sprintf("%-20s %.3f", estimate.label, estimate.value))
+	}
+
+	s := promptui.Select{
+		Label: "Growth Method",
+		Items: items,
+	}
+
+	i, _, err := s.Run()
+	if err != nil {
+		return 0, fmt.Errorf("an error occurred when selecting the growth method: %s", err)
+	}
+
+	return promptFloat(prompt, estimates[i].value, promptInfo)
 }
 
 func getFlagOrPromptInt(
@@ -549,14 +1235,134 @@ func getFlagOrPromptInt(
 	return value, nil
 }
 
-func selectDiscountRateOpt() string {
+// currentEstimate is a current FCF or dividend figure produced by a normalization method.
+type currentEstimate struct {
+	method string
+	label  string
+	value  int
+}
+
+// currentEstimates returns the current figure produced by each normalization method.
+// Methods that can't be applied, e.g. a log-linear trend through negative values, are skipped.
+func currentEstimates(series, revenue []int) []currentEstimate {
+	estimates := []currentEstimate{
+		{method: "latest", label: "Latest FY", value: series[len(series)-1]},
+		{
+			method: "mean",
+			label:  fmt.Sprintf("%d-Year Mean", len(series)),
+			value:  int(calc.Mean(series)),
+		},
+		{method: "median", label: "Median", value: int(calc.Median(series))},
+		{
+			method: "trimmed-mean",
+			label:  "Trimmed Mean",
+			value:  int(calc.TrimmedMean(series, trimmedMeanShare)),
+		},
+	}
+
+	if trend, _, err := calc.LogLinearTrend(series); err == nil {
+		estimates = append(
+			estimates,
+			currentEstimate{method: "trend", label: "Log-Linear Trend", value: int(trend)},
+		)
+	}
+
+	if len(revenue) > 0 {
+		if normalized, err := calc.MarginNormalized(series, revenue); err == nil {
+			estimates = append(
+				estimates,
+				currentEstimate{method: "margin", label: "Margin-Normalized", value: int(normalized)},
+			)
+		}
+	}
+
+	return estimates
+}
+
+// getFlagOrSelectCurrent returns the current figure from a flag, or from the normalization method flag,
+// otherwise it prompts for a normalization method and then for the figure, defaulting to the method's value.
+func getFlagOrSelectCurrent(
+	cCtx *cli.Context,
+	flagName, prompt, promptInfo string,
+	series, revenue []int,
+) (int, error) {
+	value := cCtx.Int(flagName)
+	if value != 0 {
+		return value, nil
+	}
+
+	if len(series) == 0 {
+		return 0, fmt.Errorf("no history to derive a %s from", strings.ToLower(prompt))
+	}
+
+	estimates := currentEstimates(series, revenue)
+
+	if method := cCtx.String("normalize"); method != "" {
+		for _, estimate := range estimates {
+			if estimate.method == method {
+				return estimate.value, nil
+			}
+		}
+		return 0, fmt.Errorf("normalization method %q is not available for this history", method)
+	}
+
+	printTip(normalizePromptInfo)
+
+	items := make([]string, 0, len(estimates))
+	for _, estimate := range estimates {
+		items = append(items, fmt.Sprintf("%-18s %d", estimate.label, estimate.value))
+	}
+
+	s := promptui.Select{
+		Label: "Normalization Method",
+		Items: items,
+	}
+
+	i, _, err := s.Run()
+	if err != nil {
+		return 0, fmt.Errorf("an error occurred when selecting the normalization method: %s", err)
+	}
+
+	return promptInt(prompt, estimates[i].value, promptInfo)
+}
+
+func getFlagOrSelect(
+	cCtx *cli.Context,
+	flagName, label, promptInfo string,
+	items []string,
+) (string, error) {
+	value := cCtx.String(flagName)
+	if value != "" {
+		return value, nil
+	}
+
+	printTip(promptInfo)
+
+	s := promptui.Select{
+		Label: label,
+		Items: items,
+	}
+
+	_, response, err := s.Run()
+	if err != nil {
+		return "", fmt.Errorf(
+			"an error occurred when selecting the %s: %s",
+			strings.ToLower(label),
+			err,
+		)
+	}
+
+	return response, nil
+}
+
+func selectDiscountRateOpt(items []string) string {
 	printTip(
 		"There are a few options for calculating a discount rate. Choose which one you would like to use.",
 	)
 
 	s := promptui.Select{
 		Label: "Discount Rate Options",
-		Items: []string{"WACC", "CV Weighted WACC", "Custom Input"},
+		Items: items,
 	}
 
 	_, response, err := s.Run()
@@ -2,6 +2,7 @@ package main
 
 import (
 	"errors"
+	"math"
 	"os"
 
 	"github.com/shanehull/quickval/internal/calc"
@@ -31,11 +32,26 @@ var dividendDiscountCommand = &cli.Command{
 			Value: 0,
 			Usage: "current cash paid for dividends paid of the company",
 		},
+		&cli.StringFlag{
+			Name:  "normalize",
+			Value: "",
+			Usage: "how to normalize the current dividends (latest, mean, median, trimmed-mean or trend)",
+		},
 		&cli.Float64Flag{
 			Name:  "growth-rate",
 			Value: 0.00,
 			Usage: "annual growth rate of the free cash flow during the high-growth stage",
 		},
+		&cli.StringFlag{
+			Name:  "growth-method",
+			Value: "",
+			Usage: "how to estimate the default growth rate (cagr, log-linear or median-yoy)",
+		},
+		&cli.BoolFlag{
+			Name:  "per-share",
+			Value: false,
+			Usage: "estimate growth on dividends per share, using the diluted share history",
+		},
 		&cli.Float64Flag{
 			Name:  "perpetual-rate",
 			Value: 0.00,
@@ -51,15 +67,46 @@ var dividendDiscountCommand = &cli.Command{
 			Value: 0,
 			Usage: "FY history to retrieve for financial reports",
 		},
+		&cli.BoolFlag{
+			Name:  "mid-year",
+			Value: false,
+			Usage: "discount cash flows from the middle of each year rather than the end",
+		},
+		&cli.StringFlag{
+			Name:  "fy-end",
+			Value: "",
+			Usage: "end date (YYYY-MM-DD) of the latest fiscal year, to value from a partial first year (stub period)",
+		},
+		&cli.StringFlag{
+			Name:  "valuation-date",
+			Value: "",
+			Usage: "date (YYYY-MM-DD) of the valuation when using --fy-end (defaults to today)",
+		},
+		&cli.StringFlag{
+			Name:  "discount-curve",
+			Value: "",
+			Usage: "comma separated discount rates for each projected year, or a file of them, e.g. 0.12,0.11,0.10 (the discount rate applies after the curve and to the terminal value)",
+		},
+		&cli.BoolFlag{
+			Name:  "sensitivity",
+			Value: false,
+			Usage: "render fair value sensitivity matrices",
+		},
+		&cli.Float64Flag{
+			Name:  "sensitivity-step",
+			Value: 0.01,
+			Usage: "the step between discount and growth rates in the sensitivity matrices",
+		},
 	},
 	Action: func(cCtx *cli.Context) error {
 		writer := output.NewWriter(os.Stdout)
 
-		data, fyHistory, discountRate, err := doCommonSetup(
-			cCtx,
-			writer,
-			quickfs.WithCFFDividends(),
-		)
+		qfsOpts := []quickfs.ConfigOption{quickfs.WithCFFDividends()}
+		if cCtx.Bool("per-share") {
+			qfsOpts = append(qfsOpts, quickfs.WithShareHistory())
+		}
+
+		data, fyHistory, discountRate, err := doEquitySetup(cCtx, writer, qfsOpts...)
 		if err != nil {
 			return err
 		}
@@ -68,23 +115,36 @@ var dividendDiscountCommand = &cli.Command{
 			return errors.New("no dividend history")
 		}
 
+		modelOpts, err := discountingOpts(cCtx, writer, discountRate)
+		if err != nil {
+			return err
+		}
+
+		var breakdown calc.Breakdown
+
+		history, err := growthHistory(cCtx, data.CFFDividends, &data)
+		if err != nil {
+			return err
+		}
+
 		growthRate, err := getFlagOrPromptGrowthRate(
 			cCtx,
 			"growth-rate",
 			"Growth Rate",
 			growthPromptInfo,
-			data.CFFDividends,
+			history,
 		)
 		if err != nil {
 			return err
 		}
 
-		currentDividends, err := getFlagOrPromptInt(
+		currentDividends, err := getFlagOrSelectCurrent(
 			cCtx,
 			"current-dividends",
 			"Current Cash Paid for Dividends",
 			dividendsPromptInfo,
-			data.CFFDividends[len(data.CFFDividends)-1],
+			data.CFFDividends,
+			nil,
 		)
 		if err != nil {
 			return err
@@ -117,17 +177,69 @@ var dividendDiscountCommand = &cli.Command{
 			fyHistory,
 			data.Shares,
 			discountRate,
+			append(modelOpts, calc.WithBreakdown(&breakdown))...,
 		)
 		if err != nil {
 			return err
 		}
 
+		impliedReturn, err := calc.ImpliedDiscountRate(
+			data.Price,
+			perpetualRate,
+			func(rate float64) (float64, error) {
+				fairValue, _, err := calc.DDMTwoStage(
+					currentDividends,
+					growthRate,
+					perpetualRate,
+					fyHistory,
+					data.Shares,
+					rate,
+					modelOpts...,
+				)
+				return fairValue, err
+			},
+		)
+		if err != nil {
+			// the price can be out of reach at any discount rate, which doesn't invalidate the valuation
+			impliedReturn = math.NaN()
+		}
+
 		upside, err := calc.Upside(fairValue, data.Price)
 		if err != nil {
 			return err
 		}
 
-		writer.Projected(projectedDividends, growthRate, expectedReturn, upside)
+		writer.Projected(projectedDividends, growthRate, expectedReturn, impliedReturn, upside)
+
+		if cCtx.String("discount-curve") != "" {
+			writer.DiscountCurve(breakdown)
+		}
+
+		if cCtx.Bool("sensitivity") {
+			writeSensitivity(
+				writer,
+				cCtx.Float64("sensitivity-step"),
+				discountRate,
+				growthRate,
+				perpetualRate,
+				"Perpetual Rate",
+				"%.3f",
+				cCtx.Float64("sensitivity-step")/2,
+				func(rate, growth, terminal float64) (float64, error) {
+					fairValue, _, err := calc.DDMTwoStage(
+						currentDividends,
+						growth,
+						terminal,
+						fyHistory,
+						data.Shares,
+						rate,
+						modelOpts...,
+					)
+					return fairValue, err
+				},
+			)
+		}
+
 		writer.FairValue(fairValue)
 		writer.Render()
 		return nil
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"errors"
+	"os"
+
+	"github.com/shanehull/quickval/internal/calc"
+	"github.com/shanehull/quickval/internal/output"
+	"github.com/shanehull/quickval/internal/quickfs"
+	"github.com/urfave/cli/v2"
+)
+
+var epvCommand = &cli.Command{
+	Name:        "epv",
+	Aliases:     []string{"earnings-power"},
+	Description: "Calculates Greenwald's Earnings Power Value (EPV) from normalized operating earnings, and compares it to a two-stage growth DCF to show how much of the price is paying for growth.",
+	Usage:       "Performs an Earnings Power Value model.",
+	Flags: []cli.Flag{
+		&cli.Float64Flag{
+			Name:  "risk-free",
+			Value: 0.00,
+			Usage: "the risk free rate in decimal format",
+		},
+		&cli.Float64Flag{
+			Name:  "risk-premium",
+			Value: 0.00,
+			Usage: "the equity risk premium rate in decimal format",
+		},
+		&cli.Float64Flag{
+			Name:  "ebit-margin",
+			Value: 0.00,
+			Usage: "normalized EBIT margin (defaults to the average of the EBIT margin history)",
+		},
+		&cli.IntFlag{
+			Name:  "maintenance-capex",
+			Value: 0,
+			Usage: "capex required to maintain current earnings (defaults to the average D&A)",
+		},
+		&cli.IntFlag{
+			Name:  "current-fcf",
+			Value: 0,
+			Usage: "current free cash flow of the company, for the growth DCF",
+		},
+		&cli.StringFlag{
+			Name:  "normalize",
+			Value: "",
+			Usage: "how to normalize the current FCF (latest, mean, median, trimmed-mean, trend or margin)",
+		},
+		&cli.Float64Flag{
+			Name:  "growth-rate",
+			Value: 0.00,
+			Usage: "annual growth rate of the free cash flow during the high-growth stage of the growth DCF",
+		},
+		&cli.StringFlag{
+			Name:  "growth-method",
+			Value: "",
+			Usage: "how to estimate the default growth rate (cagr, log-linear, median-yoy or fundamental)",
+		},
+		&cli.Float64Flag{
+			Name:  "perpetual-rate",
+			Value: 0.00,
+			Usage: "perpetual growth rate of the free cash flow after the high-growth stage of the growth DCF",
+		},
+		&cli.IntFlag{
+			Name:  "fy-history",
+			Value: 0,
+			Usage: "FY history to retrieve for financial reports",
+		},
+	},
+	Action: func(cCtx *cli.Context) error {
+		writer := output.NewWriter(os.Stdout)
+
+		data, fyHistory, discountRate, err := doEnterpriseSetup(
+			cCtx,
+			writer,
+			quickfs.WithFCF(),
+			quickfs.WithRevenue(),
+			quickfs.WithEBIT(),
+			quickfs.WithDepreciation(),
+			quickfs.WithROIC(),
+			quickfs.WithBalanceSheet(),
+		)
+		if err != nil {
+			return err
+		}
+
+		// the capitalized NOPAT is unlevered, so the EPV values the enterprise
+		var breakdown calc.Breakdown
+		bridge := equityBridge(&data)
+
+		if len(data.Revenue) < 1 {
+			return errors.New("no revenue history")
+		}
+
+		margins, err := calc.Margins(data.EBIT, data.Revenue)
+		if err != nil {
+			return err
+		}
+
+		ebitMargin, err := getFlagOrPromptFloat(
+			cCtx,
+			"ebit-margin",
+			"EBIT Margin",
+			ebitMarginInfo,
+			calc.Mean(margins),
+		)
+		if err != nil {
+			return err
+		}
+
+		var depreciation int
+		if len(data.Depreciation) > 0 {
+			depreciation = data.Depreciation[len(data.Depreciation)-1]
+		}
+
+		maintenanceCapex, err := getFlagOrPromptInt(
+			cCtx,
+			"maintenance-capex",
+			"Maintenance Capex",
+			maintCapexInfo,
+			int(calc.Mean(data.Depreciation)),
+		)
+		if err != nil {
+			return err
+		}
+
+		revenue := data.Revenue[len(data.Revenue)-1]
+
+		epv, adjustedEarnings, err := calc.EarningsPowerValue(
+			revenue,
+			ebitMargin,
+			data.TaxRate,
+			depreciation,
+			maintenanceCapex,
+			data.Shares,
+			discountRate,
+			calc.WithEquityBridge(bridge),
+			calc.WithBreakdown(&breakdown),
+		)
+		if err != nil {
+			return err
+		}
+
+		growthRate, err := getFlagOrPromptGrowthRate(
+			cCtx,
+			"growth-rate",
+			"Growth Rate",
+			growthPromptInfo,
+			data.FCFHistory,
+			fundamentalGrowthEstimate(cCtx, &data)...,
+		)
+		if err != nil {
+			return err
+		}
+
+		currentFCF, err := getFlagOrSelectCurrent(
+			cCtx,
+			"current-fcf",
+			"Current FCF",
+			fcfPromptInfo,
+			data.FCFHistory,
+			data.Revenue,
+		)
+		if err != nil {
+			return err
+		}
+
+		perpetualRate, err := getFlagOrPromptFloat(
+			cCtx,
+			"perpetual-rate",
+			"Perpetual Growth Rate",
+			perpetualGrowthInfo,
+			defaultPerpetualRate,
+		)
+		if err != nil {
+			return err
+		}
+
+		growthValue, _, err := calc.DCFTwoStage(
+			currentFCF,
+			growthRate,
+			perpetualRate,
+			fyHistory,
+			data.Shares,
+			discountRate,
+		)
+		if err != nil {
+			return err
+		}
+
+		writer.EquityBridge(breakdown, bridge, data.Shares)
+		writer.EarningsPower(
+			ebitMargin,
+			float64(revenue)*ebitMargin,
+			data.TaxRate,
+			depreciation,
+			maintenanceCapex,
+			adjustedEarnings,
+			epv,
+			growthValue,
+			data.Price,
+		)
+		writer.FairValue(epv)
+		writer.Render()
+		return nil
+	},
+}
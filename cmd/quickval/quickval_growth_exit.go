@@ -31,21 +31,95 @@ var growthExitCommand = &cli.Command{
 			Value: 0,
 			Usage: "override the current FCF with a normalized number",
 		},
+		&cli.StringFlag{
+			Name:  "normalize",
+			Value: "",
+			Usage: "how to normalize the current FCF (latest, mean, median, trimmed-mean, trend or margin)",
+		},
 		&cli.Float64Flag{
 			Name:  "growth-rate",
 			Value: 0.00,
 			Usage: "override the growth rate with your own number",
 		},
+		&cli.StringFlag{
+			Name:  "growth-method",
+			Value: "",
+			Usage: "how to estimate the default growth rate (cagr, log-linear, median-yoy or fundamental)",
+		},
+		&cli.BoolFlag{
+			Name:  "per-share",
+			Value: false,
+			Usage: "estimate growth on FCF per share, using the diluted share history",
+		},
+		&cli.Float64Flag{
+			Name:  "share-change",
+			Value: 0.00,
+			Usage: "projected annual dilution of the share count, e.g. 0.02 for net issuance of 2% a year (buybacks are already paid for from the FCF)",
+		},
 		&cli.IntFlag{
 			Name:  "fy-history",
 			Value: 0,
 			Usage: "override the growth rate with your own number",
 		},
+		&cli.StringFlag{
+			Name:  "cash-flow",
+			Value: string(quickfs.CashFlowFCF),
+			Usage: "cash flow basis to value (fcf, fcf-sbc, owner-earnings, net-income or fcfe)",
+		},
+		&cli.BoolFlag{
+			Name:  "unlevered",
+			Value: false,
+			Usage: "value unlevered FCF (FCFF) as an enterprise value, then deduct net debt, minority interest and preferred equity",
+		},
+		&cli.BoolFlag{
+			Name:  "mid-year",
+			Value: false,
+			Usage: "discount cash flows from the middle of each year rather than the end",
+		},
+		&cli.StringFlag{
+			Name:  "fy-end",
+			Value: "",
+			Usage: "end date (YYYY-MM-DD) of the latest fiscal year, to value from a partial first year (stub period)",
+		},
+		&cli.StringFlag{
+			Name:  "valuation-date",
+			Value: "",
+			Usage: "date (YYYY-MM-DD) of the valuation when using --fy-end (defaults to today)",
+		},
+		&cli.StringFlag{
+			Name:  "discount-curve",
+			Value: "",
+			Usage: "comma separated discount rates for each projected year, or a file of them, e.g. 0.12,0.11,0.10 (the discount rate applies after the curve and to the terminal value)",
+		},
+		&cli.BoolFlag{
+			Name:  "sensitivity",
+			Value: false,
+			Usage: "render fair value sensitivity matrices",
+		},
+		&cli.Float64Flag{
+			Name:  "sensitivity-step",
+			Value: 0.01,
+			Usage: "the step between discount and growth rates in the sensitivity matrices",
+		},
 	},
 	Action: func(cCtx *cli.Context) error {
 		writer := output.NewWriter(os.Stdout)
 
-		data, fyHistory, discountRate, err := doCommonSetup(cCtx, writer, quickfs.WithFCF())
+		setup, err := doDCFSetup(cCtx, writer)
+		if err != nil {
+			return err
+		}
+
+		var (
+			data         = setup.data
+			fyHistory    = setup.fyHistory
+			discountRate = setup.discountRate
+			bridge       = setup.bridge
+			modelOpts    = setup.modelOpts
+			breakdown    calc.Breakdown
+		)
+
+		history, err := growthHistory(cCtx, data.FCFHistory, &data)
 		if err != nil {
 			return err
 		}
@@ -55,18 +129,20 @@ var growthExitCommand = &cli.Command{
 			"growth-rate",
 			"Growth Rate",
 			growthPromptInfo,
-			data.FCFHistory,
+			history,
+			fundamentalGrowthEstimate(cCtx, &data)...,
 		)
 		if err != nil {
 			return err
 		}
 
-		currentFCF, err := getFlagOrPromptInt(
+		currentFCF, err := getFlagOrSelectCurrent(
 			cCtx,
 			"current-fcf",
 			"Current FCF",
 			fcfPromptInfo,
-			data.FCFHistory[len(data.FCFHistory)-1],
+			data.FCFHistory,
+			data.Revenue,
 		)
 		if err != nil {
 			return err
@@ -87,6 +163,11 @@ var growthExitCommand = &cli.Command{
 			return err
 		}
 
+		// compounding a negative FCF is meaningless, it needs a turnaround projection
+		if currentFCF <= 0 {
+			return cli.Exit("the current FCF is not positive, use the turnaround command", 127)
+		}
+
 		expectedReturn, err := calc.ExpectedReturn(
 			growthRate,
 			float64(currentFCF)/float64(data.Shares),
@@ -103,17 +184,79 @@ var growthExitCommand = &cli.Command{
 			fyHistory,
 			data.Shares,
 			discountRate,
+			append(modelOpts, calc.WithBreakdown(&breakdown))...,
 		)
 		if err != nil {
 			return err
 		}
 
+		impliedReturn, err := calc.ImpliedDiscountRate(
+			data.Price,
+			minImpliedReturn,
+			func(rate float64) (float64, error) {
+				fairValue, _, err := calc.DCFGrowthExit(
+					currentFCF,
+					growthRate,
+					exitMultiple,
+					fyHistory,
+					data.Shares,
+					rate,
+					modelOpts...,
+				)
+				return fairValue, err
+			},
+		)
+		if err != nil {
+			// the price can be out of reach at any discount rate, which doesn't invalidate the valuation
+			impliedReturn = math.NaN()
+		}
+
 		upside, err := calc.Upside(fairValue, data.Price)
 		if err != nil {
 			return err
 		}
 
-		writer.Projected(projectedFCF, growthRate, expectedReturn, upside)
+		if cCtx.Bool("unlevered") {
+			writer.EquityBridge(breakdown, bridge, data.Shares)
+		}
+
+		warnings, err := terminalWarnings(cCtx, breakdown)
+		if err != nil {
+			return err
+		}
+
+		writer.Projected(projectedFCF, growthRate, expectedReturn, impliedReturn, upside)
+		writer.TerminalValue(breakdown, warnings)
+
+		if cCtx.String("discount-curve") != "" {
+			writer.DiscountCurve(breakdown)
+		}
+
+		if cCtx.Bool("sensitivity") {
+			writeSensitivity(
+				writer,
+				cCtx.Float64("sensitivity-step"),
+				discountRate,
+				growthRate,
+				exitMultiple,
+				"Exit Multiple",
+				"%.1f",
+				sensitivityMultipleStep,
+				func(rate, growth, terminal float64) (float64, error) {
+					fairValue, _, err := calc.DCFGrowthExit(
+						currentFCF,
+						growth,
+						terminal,
+						fyHistory,
+						data.Shares,
+						rate,
+						modelOpts...,
+					)
+					return fairValue, err
+				},
+			)
+		}
+
 		writer.FairValue(fairValue)
 		writer.Render()
 
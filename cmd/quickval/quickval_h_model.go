@@ -0,0 +1,247 @@
+package main
+
+import (
+	"errors"
+	"math"
+	"os"
+
+	"github.com/shanehull/quickval/internal/calc"
+	"github.com/shanehull/quickval/internal/output"
+	"github.com/shanehull/quickval/internal/quickfs"
+	"github.com/urfave/cli/v2"
+)
+
+var hModelCommand = &cli.Command{
+	Name:        "h-model",
+	Aliases:     []string{"ddmh"},
+	Description: "Performs a Fuller-Hsia H-model dividend discount model, where dividend growth declines linearly from a short-term rate to a long-term rate over twice the half-life",
+	Usage:       "Performs an H-model DDM.",
+	Flags: []cli.Flag{
+		&cli.Float64Flag{
+			Name:  "risk-free",
+			Value: 0.00,
+			Usage: "the risk free rate in decimal format",
+		},
+		&cli.Float64Flag{
+			Name:  "risk-premium",
+			Value: 0.00,
+			Usage: "the equity risk premium rate in decimal format",
+		},
+		&cli.IntFlag{
+			Name:  "current-dividends",
+			Value: 0,
+			Usage: "current cash paid for dividends paid of the company",
+		},
+		&cli.StringFlag{
+			Name:  "normalize",
+			Value: "",
+			Usage: "how to normalize the current dividends (latest, mean, median, trimmed-mean or trend)",
+		},
+		&cli.Float64Flag{
+			Name:  "growth-rate",
+			Value: 0.00,
+			Usage: "short-term annual growth rate of the dividends",
+		},
+		&cli.StringFlag{
+			Name:  "growth-method",
+			Value: "",
+			Usage: "how to estimate the default growth rate (cagr, log-linear or median-yoy)",
+		},
+		&cli.BoolFlag{
+			Name:  "per-share",
+			Value: false,
+			Usage: "estimate growth on dividends per share, using the diluted share history",
+		},
+		&cli.Float64Flag{
+			Name:  "perpetual-rate",
+			Value: 0.00,
+			Usage: "long-term (perpetual) growth rate of the dividends",
+		},
+		&cli.Float64Flag{
+			Name:  "half-life",
+			Value: 0.00,
+			Usage: "half-life in years of the period where growth declines to the long-term rate",
+		},
+		&cli.IntFlag{
+			Name:  "fy-history",
+			Value: 0,
+			Usage: "FY history to retrieve for financial reports",
+		},
+		&cli.BoolFlag{
+			Name:  "mid-year",
+			Value: false,
+			Usage: "discount cash flows from the middle of each year rather than the end",
+		},
+		&cli.StringFlag{
+			Name:  "fy-end",
+			Value: "",
+			Usage: "end date (YYYY-MM-DD) of the latest fiscal year, to value from a partial first year (stub period)",
+		},
+		&cli.StringFlag{
+			Name:  "valuation-date",
+			Value: "",
+			Usage: "date (YYYY-MM-DD) of the valuation when using --fy-end (defaults to today)",
+		},
+		&cli.BoolFlag{
+			Name:  "sensitivity",
+			Value: false,
+			Usage: "render fair value sensitivity matrices",
+		},
+		&cli.Float64Flag{
+			Name:  "sensitivity-step",
+			Value: 0.01,
+			Usage: "the step between discount and growth rates in the sensitivity matrices",
+		},
+	},
+	Action: func(cCtx *cli.Context) error {
+		writer := output.NewWriter(os.Stdout)
+
+		qfsOpts := []quickfs.ConfigOption{quickfs.WithCFFDividends()}
+		if cCtx.Bool("per-share") {
+			qfsOpts = append(qfsOpts, quickfs.WithShareHistory())
+		}
+
+		data, _, discountRate, err := doEquitySetup(cCtx, writer, qfsOpts...)
+		if err != nil {
+			return err
+		}
+
+		if len(data.CFFDividends) < 1 {
+			return errors.New("no dividend history")
+		}
+
+		modelOpts, err := discountingOpts(cCtx, writer, discountRate)
+		if err != nil {
+			return err
+		}
+
+		history, err := growthHistory(cCtx, data.CFFDividends, &data)
+		if err != nil {
+			return err
+		}
+
+		growthRate, err := getFlagOrPromptGrowthRate(
+			cCtx,
+			"growth-rate",
+			"Short-Term Growth Rate",
+			growthPromptInfo,
+			history,
+		)
+		if err != nil {
+			return err
+		}
+
+		currentDividends, err := getFlagOrSelectCurrent(
+			cCtx,
+			"current-dividends",
+			"Current Cash Paid for Dividends",
+			dividendsPromptInfo,
+			data.CFFDividends,
+			nil,
+		)
+		if err != nil {
+			return err
+		}
+
+		perpetualRate, err := getFlagOrPromptFloat(
+			cCtx,
+			"perpetual-rate",
+			"Long-Term Growth Rate",
+			perpetualGrowthInfo,
+			defaultPerpetualRate,
+		)
+		if err != nil {
+			return err
+		}
+
+		halfLife, err := getFlagOrPromptFloat(
+			cCtx,
+			"half-life",
+			"Half-Life",
+			halfLifePromptInfo,
+			defaultHalfLife,
+		)
+		if err != nil {
+			return err
+		}
+
+		expectedReturn, err := calc.ExpectedReturn(
+			growthRate,
+			float64(currentDividends)/float64(data.Shares),
+			data.Price,
+		)
+		if err != nil {
+			return err
+		}
+
+		fairValue, projectedDividends, err := calc.DDMHModel(
+			currentDividends,
+			growthRate,
+			perpetualRate,
+			halfLife,
+			data.Shares,
+			discountRate,
+			modelOpts...,
+		)
+		if err != nil {
+			return err
+		}
+
+		impliedReturn, err := calc.ImpliedDiscountRate(
+			data.Price,
+			perpetualRate,
+			func(rate float64) (float64, error) {
+				fairValue, _, err := calc.DDMHModel(
+					currentDividends,
+					growthRate,
+					perpetualRate,
+					halfLife,
+					data.Shares,
+					rate,
+					modelOpts...,
+				)
+				return fairValue, err
+			},
+		)
+		if err != nil {
+			// the price can be out of reach at any discount rate, which doesn't invalidate the valuation
+			impliedReturn = math.NaN()
+		}
+
+		upside, err := calc.Upside(fairValue, data.Price)
+		if err != nil {
+			return err
+		}
+
+		writer.Projected(projectedDividends, growthRate, expectedReturn, impliedReturn, upside)
+
+		if cCtx.Bool("sensitivity") {
+			writeSensitivity(
+				writer,
+				cCtx.Float64("sensitivity-step"),
+				discountRate,
+				growthRate,
+				perpetualRate,
+				"Perpetual Rate",
+				"%.3f",
+				cCtx.Float64("sensitivity-step")/2,
+				func(rate, growth, terminal float64) (float64, error) {
+					fairValue, _, err := calc.DDMHModel(
+						currentDividends,
+						growth,
+						terminal,
+						halfLife,
+						data.Shares,
+						rate,
+						modelOpts...,
+					)
+					return fairValue, err
+				},
+			)
+		}
+
+		writer.FairValue(fairValue)
+		writer.Render()
+		return nil
+	},
+}
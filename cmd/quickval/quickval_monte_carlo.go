@@ -0,0 +1,322 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shanehull/quickval/internal/calc"
+	"github.com/shanehull/quickval/internal/output"
+	"github.com/shanehull/quickval/internal/quickfs"
+	"github.com/urfave/cli/v2"
+)
+
+var monteCarloCommand = &cli.Command{
+	Name:    "monte-carlo",
+	Aliases: []string{"mc"},
+	Description: "Runs a growth-exit or two-stage DCF model many times with inputs sampled from distributions. " +
+		"Distributions are given as normal:mean,stddev, triangular:min,mode,max, uniform:min,max, " +
+		"bootstrap (resampled from the FCF history), or a single fixed value.",
+	Usage: "Performs a Monte Carlo simulation of a DCF model.",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "model",
+			Value: "",
+			Usage: "the DCF model to simulate (growth-exit or two-stage)",
+		},
+		&cli.Float64Flag{
+			Name:  "risk-free",
+			Value: 0.00,
+			Usage: "the risk-free rate in decimal format",
+		},
+		&cli.Float64Flag{
+			Name:  "risk-premium",
+			Value: 0.00,
+			Usage: "the equity risk premium rate in decimal format",
+		},
+		&cli.IntFlag{
+			Name:  "current-fcf",
+			Value: 0,
+			Usage: "override the current FCF with a normalized number",
+		},
+		&cli.StringFlag{
+			Name:  "normalize",
+			Value: "",
+			Usage: "how to normalize the current FCF (latest, mean, median, trimmed-mean, trend or margin)",
+		},
+		&cli.Float64Flag{
+			Name:  "exit-multiple",
+			Value: 0.00,
+			Usage: "exit multiple to apply to the final year FCF (growth-exit)",
+		},
+		&cli.Float64Flag{
+			Name:  "perpetual-rate",
+			Value: 0.00,
+			Usage: "perpetual growth rate of the free cash flow after the high-growth stage (two-stage)",
+		},
+		&cli.StringFlag{
+			Name:  "growth-dist",
+			Value: "bootstrap",
+			Usage: "distribution of the growth rate",
+		},
+		&cli.StringFlag{
+			Name:  "fcf-dist",
+			Value: "",
+			Usage: "distribution of the current FCF (defaults to the current FCF)",
+		},
+		&cli.StringFlag{
+			Name:  "terminal-dist",
+			Value: "",
+			Usage: "distribution of the exit multiple or perpetual rate (defaults to the exit multiple or perpetual rate)",
+		},
+		&cli.StringFlag{
+			Name:  "discount-dist",
+			Value: "",
+			Usage: "distribution of the discount rate (defaults to the discount rate)",
+		},
+		&cli.IntFlag{
+			Name:  "runs",
+			Value: 10000,
+			Usage: "number of simulation runs",
+		},
+		&cli.Int64Flag{
+			Name:  "seed",
+			Value: 0,
+			Usage: "seed for the random number generator, for reproducible runs (defaults to the current time)",
+		},
+		&cli.IntFlag{
+			Name:  "bins",
+			Value: 10,
+			Usage: "number of bins in the fair value histogram",
+		},
+		&cli.IntFlag{
+			Name:  "fy-history",
+			Value: 0,
+			Usage: "FY history to retrieve for financial reports",
+		},
+	},
+	Action: func(cCtx *cli.Context) error {
+		writer := output.NewWriter(os.Stdout)
+
+		data, fyHistory, discountRate, err := doCommonSetup(
+			cCtx,
+			writer,
+			quickfs.WithFCF(),
+			quickfs.WithRevenue(),
+		)
+		if err != nil {
+			return err
+		}
+
+		model, err := getFlagOrSelect(
+			cCtx,
+			"model",
+			"DCF Model",
+			modelPromptInfo,
+			[]string{growthExitCommand.Name, twoStageCommand.Name},
+		)
+		if err != nil {
+			return err
+		}
+
+		growthDist, err := parseDistribution(
+			cCtx.String("growth-dist"),
+			calc.YoYChanges(data.FCFHistory),
+		)
+		if err != nil {
+			return fmt.Errorf("invalid growth distribution: %w", err)
+		}
+
+		var fcfHistory []float64
+		for _, fcf := range data.FCFHistory {
+			fcfHistory = append(fcfHistory, float64(fcf))
+		}
+
+		// the current FCF is the FCF without a distribution, and the basis of the default exit multiple
+		defaultExitMultiple := model == growthExitCommand.Name &&
+			cCtx.String("terminal-dist") == "" && cCtx.Float64("exit-multiple") == 0.00
+
+		var currentFCF int
+		if cCtx.String("fcf-dist") == "" || defaultExitMultiple {
+			currentFCF, err = getFlagOrSelectCurrent(
+				cCtx,
+				"current-fcf",
+				"Current FCF",
+				fcfPromptInfo,
+				data.FCFHistory,
+				data.Revenue,
+			)
+			if err != nil {
+				return err
+			}
+
+			// compounding a negative FCF is meaningless, it needs a turnaround projection
+			if currentFCF <= 0 {
+				return cli.Exit("the current FCF is not positive, use the turnaround command", 127)
+			}
+		}
+
+		var fcfDist calc.Distribution
+		if spec := cCtx.String("fcf-dist"); spec != "" {
+			fcfDist, err = parseDistribution(spec, fcfHistory)
+			if err != nil {
+				return fmt.Errorf("invalid FCF distribution: %w", err)
+			}
+		} else {
+			fcfDist = calc.Constant(float64(currentFCF))
+		}
+
+		var terminalDist calc.Distribution
+		if spec := cCtx.String("terminal-dist"); spec != "" {
+			terminalDist, err = parseDistribution(spec, nil)
+			if err != nil {
+				return fmt.Errorf("invalid terminal distribution: %w", err)
+			}
+		} else {
+			var terminal float64
+
+			switch model {
+			case growthExitCommand.Name:
+				currentMultipleFloor := 0.0
+				if currentFCF > 0 {
+					currentMultipleFloor = math.Floor(
+						data.Price / (float64(currentFCF) / float64(data.Shares)),
+					)
+				}
+
+				terminal, err = getFlagOrPromptFloat(
+					cCtx,
+					"exit-multiple",
+					"Exit Multiple",
+					exitPromptInfo,
+					currentMultipleFloor,
+				)
+			default:
+				terminal, err = getFlagOrPromptFloat(
+					cCtx,
+					"perpetual-rate",
+					"Perpetual Growth Rate",
+					perpetualGrowthInfo,
+					defaultPerpetualRate,
+				)
+			}
+			if err != nil {
+				return err
+			}
+			terminalDist = calc.Constant(terminal)
+		}
+
+		var discountDist calc.Distribution = calc.Constant(discountRate)
+		if spec := cCtx.String("discount-dist"); spec != "" {
+			discountDist, err = parseDistribution(spec, nil)
+			if err != nil {
+				return fmt.Errorf("invalid discount rate distribution: %w", err)
+			}
+		}
+
+		var valueAt func(currentFCF int, growthRate, terminal, rate float64) (float64, error)
+
+		switch model {
+		case growthExitCommand.Name:
+			valueAt = func(currentFCF int, growthRate, terminal, rate float64) (float64, error) {
+				fairValue, _, err := calc.DCFGrowthExit(
+					currentFCF,
+					growthRate,
+					terminal,
+					fyHistory,
+					data.Shares,
+					rate,
+				)
+				return fairValue, err
+			}
+		case twoStageCommand.Name:
+			valueAt = func(currentFCF int, growthRate, terminal, rate float64) (float64, error) {
+				fairValue, _, err := calc.DCFTwoStage(
+					currentFCF,
+					growthRate,
+					terminal,
+					fyHistory,
+					data.Shares,
+					rate,
+				)
+				return fairValue, err
+			}
+		default:
+			return cli.Exit("unsupported model option", 127)
+		}
+
+		seed := cCtx.Int64("seed")
+		if !cCtx.IsSet("seed") {
+			seed = time.Now().UnixNano()
+		}
+
+		sim, err := calc.MonteCarlo(
+			cCtx.Int("runs"),
+			seed,
+			calc.SimulationInputs{
+				CurrentFCF:   fcfDist,
+				GrowthRate:   growthDist,
+				Terminal:     terminalDist,
+				DiscountRate: discountDist,
+			},
+			valueAt,
+		)
+		if err != nil {
+			return err
+		}
+
+		writer.Simulation(sim, data.Price, seed, cCtx.Int("bins"))
+		writer.FairValue(sim.Percentile(0.5))
+		writer.Render()
+
+		return nil
+	},
+}
+
+// parseDistribution parses a distribution spec, e.g. "normal:0.10,0.02". Bootstrapped distributions resample the history.
+func parseDistribution(spec string, history []float64) (calc.Distribution, error) {
+	name, args, _ := strings.Cut(strings.ToLower(strings.TrimSpace(spec)), ":")
+
+	var params []float64
+	if args != "" {
+		for _, arg := range strings.Split(args, ",") {
+			param, err := strconv.ParseFloat(strings.TrimSpace(arg), 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid parameter %q in %q", arg, spec)
+			}
+			params = append(params, param)
+		}
+	}
+
+	switch name {
+	case "normal":
+		if len(params) != 2 {
+			return nil, fmt.Errorf("normal distribution requires a mean and standard deviation")
+		}
+		return calc.Normal{Mean: params[0], StdDev: params[1]}, nil
+	case "triangular":
+		if len(params) != 3 || params[0] > params[1] || params[1] > params[2] {
+			return nil, fmt.Errorf("triangular distribution requires an ordered min, mode and max")
+		}
+		return calc.Triangular{Min: params[0], Mode: params[1], Max: params[2]}, nil
+	case "uniform":
+		if len(params) != 2 || params[0] > params[1] {
+			return nil, fmt.Errorf("uniform distribution requires an ordered min and max")
+		}
+		return calc.Uniform{Min: params[0], Max: params[1]}, nil
+	case "bootstrap":
+		if len(history) == 0 {
+			return nil, fmt.Errorf("no history to bootstrap from")
+		}
+		return calc.Bootstrap(history), nil
+	default:
+		value, err := strconv.ParseFloat(name, 64)
+		if err != nil {
+			return nil, fmt.Errorf("unsupported distribution %q", spec)
+		}
+		return calc.Constant(value), nil
+	}
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"errors"
+	"math"
+	"os"
+
+	"github.com/shanehull/quickval/internal/calc"
+	"github.com/shanehull/quickval/internal/output"
+	"github.com/shanehull/quickval/internal/quickfs"
+	"github.com/urfave/cli/v2"
+)
+
+var payoutDividendCommand = &cli.Command{
+	Name:    "payout-ddm",
+	Aliases: []string{"ddmp"},
+	Description: "Performs a multi-stage dividend discount model, where dividends are projected from earnings growth and a payout ratio " +
+		"that moves from the current level to a mature payout as growth fades to the perpetual rate.",
+	Usage: "Performs a payout-transition DDM model.",
+	Flags: []cli.Flag{
+		&cli.Float64Flag{
+			Name:  "risk-free",
+			Value: 0.00,
+			Usage: "the risk free rate in decimal format",
+		},
+		&cli.Float64Flag{
+			Name:  "risk-premium",
+			Value: 0.00,
+			Usage: "the equity risk premium rate in decimal format",
+		},
+		&cli.IntFlag{
+			Name:  "current-earnings",
+			Value: 0,
+			Usage: "current net income of the company",
+		},
+		&cli.StringFlag{
+			Name:  "normalize",
+			Value: "",
+			Usage: "how to normalize the current earnings (latest, mean, median, trimmed-mean or trend)",
+		},
+		&cli.Float64Flag{
+			Name:  "growth-rate",
+			Value: 0.00,
+			Usage: "annual growth rate of the earnings during the high-growth stage",
+		},
+		&cli.StringFlag{
+			Name:  "growth-method",
+			Value: "",
+			Usage: "how to estimate the default growth rate (cagr, log-linear or median-yoy)",
+		},
+		&cli.IntFlag{
+			Name:  "transition-years",
+			Value: 0,
+			Usage: "number of years over which growth fades and the payout converges to the mature payout",
+		},
+		&cli.Float64Flag{
+			Name:  "perpetual-rate",
+			Value: 0.00,
+			Usage: "perpetual growth rate of the earnings after the transition stage",
+		},
+		&cli.Float64Flag{
+			Name:  "current-payout",
+			Value: 0.00,
+			Usage: "share of earnings paid as dividends during the high-growth stage (defaults to the historical payout)",
+		},
+		&cli.Float64Flag{
+			Name:  "mature-payout",
+			Value: 0.00,
+			Usage: "share of earnings paid as dividends once mature (defaults to 1 - perpetual rate / ROE)",
+		},
+		&cli.IntFlag{
+			Name:  "fy-history",
+			Value: 0,
+			Usage: "FY history to retrieve for financial reports",
+		},
+		&cli.BoolFlag{
+			Name:  "mid-year",
+			Value: false,
+			Usage: "discount cash flows from the middle of each year rather than the end",
+		},
+		&cli.StringFlag{
+			Name:  "fy-end",
+			Value: "",
+			Usage: "end date (YYYY-MM-DD) of the latest fiscal year, to value from a partial first year (stub period)",
+		},
+		&cli.StringFlag{
+			Name:  "valuation-date",
+			Value: "",
+			Usage: "date (YYYY-MM-DD) of the valuation when using --fy-end (defaults to today)",
+		},
+		&cli.StringFlag{
+			Name:  "discount-curve",
+			Value: "",
+			Usage: "comma separated discount rates for each projected year, or a file of them, e.g. 0.12,0.11,0.10 (the discount rate applies after the curve and to the terminal value)",
+		},
+	},
+	Action: func(cCtx *cli.Context) error {
+		writer := output.NewWriter(os.Stdout)
+
+		data, fyHistory, discountRate, err := doEquitySetup(
+			cCtx,
+			writer,
+			quickfs.WithCFFDividends(),
+			quickfs.WithNetIncome(),
+			quickfs.WithROE(),
+		)
+		if err != nil {
+			return err
+		}
+
+		if len(data.NetIncome) < 1 {
+			return errors.New("no net income history")
+		}
+
+		modelOpts, err := discountingOpts(cCtx, writer, discountRate)
+		if err != nil {
+			return err
+		}
+
+		var (
+			breakdown calc.Breakdown
+			inputs    calc.PayoutInputs
+		)
+
+		inputs.CurrentEarnings, err = getFlagOrSelectCurrent(
+			cCtx,
+			"current-earnings",
+			"Current Net Income",
+			earningsPromptInfo,
+			data.NetIncome,
+			nil,
+		)
+		if err != nil {
+			return err
+		}
+
+		inputs.GrowthRate, err = getFlagOrPromptGrowthRate(
+			cCtx,
+			"growth-rate",
+			"Growth Rate",
+			growthPromptInfo,
+			data.NetIncome,
+		)
+		if err != nil {
+			return err
+		}
+
+		transitionYears, err := getFlagOrPromptInt(
+			cCtx,
+			"transition-years",
+			"Transition Years",
+			transitionYearsInfo,
+			defaultTransitionYears,
+		)
+		if err != nil {
+			return err
+		}
+
+		inputs.PerpetualGrowthRate, err = getFlagOrPromptFloat(
+			cCtx,
+			"perpetual-rate",
+			"Perpetual Growth Rate",
+			perpetualGrowthInfo,
+			defaultPerpetualRate,
+		)
+		if err != nil {
+			return err
+		}
+
+		// a company that paid no dividends in the history starts from a zero payout
+		currentPayout, err := calc.PayoutRatio(data.CFFDividends, data.NetIncome)
+		if err != nil {
+			currentPayout = 0
+		}
+
+		inputs.CurrentPayout, err = getFlagOrPromptFloat(
+			cCtx,
+			"current-payout",
+			"Current Payout Ratio",
+			currentPayoutInfo,
+			currentPayout,
+		)
+		if err != nil {
+			return err
+		}
+
+		inputs.MaturePayout, err = getFlagOrPromptFloat(
+			cCtx,
+			"mature-payout",
+			"Mature Payout Ratio",
+			maturePayoutInfo,
+			calc.SustainablePayout(inputs.PerpetualGrowthRate, calc.Mean(data.ROE)),
+		)
+		if err != nil {
+			return err
+		}
+
+		fairValue, projections, err := calc.DDMPayoutTransition(
+			inputs,
+			fyHistory,
+			transitionYears,
+			data.Shares,
+			discountRate,
+			append(modelOpts, calc.WithBreakdown(&breakdown))...,
+		)
+		if err != nil {
+			return err
+		}
+
+		impliedReturn, err := calc.ImpliedDiscountRate(
+			data.Price,
+			inputs.PerpetualGrowthRate,
+			func(rate float64) (float64, error) {
+				fairValue, _, err := calc.DDMPayoutTransition(
+					inputs,
+					fyHistory,
+					transitionYears,
+					data.Shares,
+					rate,
+					modelOpts...,
+				)
+				return fairValue, err
+			},
+		)
+		if err != nil {
+			// the price can be out of reach at any discount rate, which doesn't invalidate the valuation
+			impliedReturn = math.NaN()
+		}
+
+		upside, err := calc.Upside(fairValue, data.Price)
+		if err != nil {
+			return err
+		}
+
+		writer.PayoutProjected(projections, inputs, impliedReturn, upside)
+
+		if cCtx.String("discount-curve") != "" {
+			writer.DiscountCurve(breakdown)
+		}
+
+		writer.FairValue(fairValue)
+		writer.Render()
+		return nil
+	},
+}
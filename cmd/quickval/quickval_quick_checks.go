@@ -0,0 +1,192 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+
+	"github.com/shanehull/quickval/internal/calc"
+	"github.com/shanehull/quickval/internal/output"
+	"github.com/shanehull/quickval/internal/quickfs"
+	"github.com/urfave/cli/v2"
+)
+
+var quickChecksCommand = &cli.Command{
+	Name:    "quick-checks",
+	Aliases: []string{"qc"},
+	Description: "Computes the Graham number, the revised Graham formula, a PEG-based Lynch fair value and the earnings yield " +
+		"versus the local bond yield, as sanity checks before spending time on a full DCF.",
+	Usage: "Computes Graham and Lynch formula quick checks.",
+	Flags: []cli.Flag{
+		&cli.Float64Flag{
+			Name:  "risk-free",
+			Value: 0.00,
+			Usage: "the risk free rate (local bond yield) in decimal format",
+		},
+		&cli.Float64Flag{
+			Name:  "eps",
+			Value: 0.00,
+			Usage: "current earnings per share (defaults to the latest net income per share)",
+		},
+		&cli.Float64Flag{
+			Name:  "book-value",
+			Value: 0.00,
+			Usage: "current book value per share (defaults to the latest book value per share)",
+		},
+		&cli.Float64Flag{
+			Name:  "growth-rate",
+			Value: 0.00,
+			Usage: "expected annual growth rate of the EPS",
+		},
+		&cli.StringFlag{
+			Name:  "growth-method",
+			Value: "",
+			Usage: "how to estimate the default growth rate (cagr, log-linear or median-yoy)",
+		},
+		&cli.IntFlag{
+			Name:  "fy-history",
+			Value: 0,
+			Usage: "FY history to retrieve for financial reports",
+		},
+	},
+	Action: func(cCtx *cli.Context) error {
+		writer := output.NewWriter(os.Stdout)
+
+		var err error
+
+		// the checks don't discount anything, so there's no discount rate to set up
+		fyHistory := cCtx.Int("fy-history")
+		if fyHistory == 0 {
+			fyHistory, err = promptInt("FY History", 5, fyHistoryPromptInfo)
+			if err != nil {
+				return err
+			}
+		}
+
+		bondYield := cCtx.Float64("risk-free")
+		if bondYield == 0.00 {
+			rates, err := countryRates(cCtx)
+			if err != nil {
+				return err
+			}
+
+			bondYield, err = promptFloat("Risk-Free Rate", rates.RiskFreeRate, rfrPromptInfo)
+			if err != nil {
+				return err
+			}
+		}
+
+		qfs := quickfs.NewQuickFS(
+			quickfs.WithAPIKey(apiKey),
+			quickfs.WithFYHistory(fyHistory),
+			quickfs.WithNetIncome(),
+			quickfs.WithBookValue(),
+			quickfs.WithCFFDividends(),
+			quickfs.WithShareHistory(),
+		)
+
+		data, err := qfs.GetData(ticker, country)
+		if err != nil {
+			return fmt.Errorf("error getting data: %s", err)
+		}
+
+		if len(data.NetIncome) < 1 {
+			return errors.New("no net income history")
+		}
+		if data.Shares <= 0 {
+			return errors.New("number of shares outstanding must be greater than zero")
+		}
+
+		writer.Data(&data)
+
+		shares := float64(data.Shares)
+
+		eps, err := getFlagOrPromptFloat(
+			cCtx,
+			"eps",
+			"EPS",
+			epsPromptInfo,
+			float64(data.NetIncome[len(data.NetIncome)-1])/shares,
+		)
+		if err != nil {
+			return err
+		}
+
+		bookValuePerShare := 0.0
+		if len(data.BookValue) > 0 {
+			bookValuePerShare = float64(data.BookValue[len(data.BookValue)-1]) / shares
+		}
+
+		bookValuePerShare, err = getFlagOrPromptFloat(
+			cCtx,
+			"book-value",
+			"Book Value per Share",
+			bookValuePromptInfo,
+			bookValuePerShare,
+		)
+		if err != nil {
+			return err
+		}
+
+		// estimate growth on EPS rather than net income, so buybacks and dilution count
+		history, err := calc.ShareAdjusted(data.NetIncome, data.SharesHistory)
+		if err != nil {
+			history = data.NetIncome
+		}
+
+		growthRate, err := getFlagOrPromptGrowthRate(
+			cCtx,
+			"growth-rate",
+			"Growth Rate",
+			growthPromptInfo,
+			history,
+		)
+		if err != nil {
+			return err
+		}
+
+		dividendYield := 0.0
+		if len(data.CFFDividends) > 0 && data.Price > 0 {
+			latestDividends := float64(data.CFFDividends[len(data.CFFDividends)-1])
+			dividendYield = latestDividends / shares / data.Price
+		}
+
+		upside := func(value float64) float64 {
+			upside, err := calc.Upside(value, data.Price)
+			if err != nil {
+				return math.NaN()
+			}
+			return upside
+		}
+
+		// a check that doesn't apply, e.g. to negative earnings, is shown as n/a
+		grahamNumber, err := calc.GrahamNumber(eps, bookValuePerShare)
+		if err != nil {
+			grahamNumber = math.NaN()
+		}
+
+		revisedGraham, err := calc.RevisedGraham(eps, growthRate, bondYield)
+		if err != nil || revisedGraham <= 0 {
+			revisedGraham = math.NaN()
+		}
+
+		lynchValue := calc.LynchFairValue(eps, growthRate, dividendYield)
+		if lynchValue <= 0 {
+			lynchValue = math.NaN()
+		}
+
+		earningsYield, err := calc.EarningsYield(eps, data.Price)
+		if err != nil {
+			return err
+		}
+
+		writer.QuickChecks(eps, bookValuePerShare, growthRate, dividendYield, data.Price)
+		writer.QuickCheck("Graham Number", grahamNumber, upside(grahamNumber))
+		writer.QuickCheck("Revised Graham Value", revisedGraham, upside(revisedGraham))
+		writer.QuickCheck("Lynch Fair Value (PEGY)", lynchValue, upside(lynchValue))
+		writer.EarningsYield(earningsYield, bondYield)
+		writer.Render()
+		return nil
+	},
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"errors"
+	"math"
+	"os"
+
+	"github.com/shanehull/quickval/internal/calc"
+	"github.com/shanehull/quickval/internal/output"
+	"github.com/shanehull/quickval/internal/quickfs"
+	"github.com/urfave/cli/v2"
+)
+
+var residualIncomeCommand = &cli.Command{
+	Name:        "residual-income",
+	Aliases:     []string{"ri"},
+	Description: "Performs a residual income (excess return) model, valuing book value plus the present value of returns earned above the cost of equity. Suited to banks and insurers, where FCF is not meaningful.",
+	Usage:       "Performs a residual income model.",
+	Flags: []cli.Flag{
+		&cli.Float64Flag{
+			Name:  "risk-free",
+			Value: 0.00,
+			Usage: "the risk free rate in decimal format",
+		},
+		&cli.Float64Flag{
+			Name:  "risk-premium",
+			Value: 0.00,
+			Usage: "the equity risk premium rate in decimal format",
+		},
+		&cli.IntFlag{
+			Name:  "current-book-value",
+			Value: 0,
+			Usage: "current book value of equity of the company",
+		},
+		&cli.Float64Flag{
+			Name:  "roe",
+			Value: 0.00,
+			Usage: "return on equity expected over the projection period",
+		},
+		&cli.Float64Flag{
+			Name:  "growth-rate",
+			Value: 0.00,
+			Usage: "annual growth rate of the book value during the projection period",
+		},
+		&cli.StringFlag{
+			Name:  "growth-method",
+			Value: "",
+			Usage: "how to estimate the default growth rate (cagr, log-linear or median-yoy)",
+		},
+		&cli.Float64Flag{
+			Name:  "perpetual-rate",
+			Value: 0.00,
+			Usage: "perpetual growth rate of the residual income after the projection period",
+		},
+		&cli.IntFlag{
+			Name:  "fy-history",
+			Value: 0,
+			Usage: "FY history to retrieve for financial reports",
+		},
+	},
+	Action: func(cCtx *cli.Context) error {
+		writer := output.NewWriter(os.Stdout)
+
+		// residual income is earned by the shareholders, so it's discounted at the cost of equity
+		data, fyHistory, discountRate, err := doEquitySetup(
+			cCtx,
+			writer,
+			quickfs.WithBookValue(),
+			quickfs.WithROE(),
+		)
+		if err != nil {
+			return err
+		}
+
+		if len(data.BookValue) < 1 {
+			return errors.New("no book value history")
+		}
+
+		roe, err := getFlagOrPromptFloat(
+			cCtx,
+			"roe",
+			"Return on Equity",
+			roePromptInfo,
+			calc.Mean(data.ROE),
+		)
+		if err != nil {
+			return err
+		}
+
+		growthRate, err := getFlagOrPromptGrowthRate(
+			cCtx,
+			"growth-rate",
+			"Book Value Growth Rate",
+			growthPromptInfo,
+			data.BookValue,
+		)
+		if err != nil {
+			return err
+		}
+
+		currentBookValue, err := getFlagOrPromptInt(
+			cCtx,
+			"current-book-value",
+			"Current Book Value",
+			bookValuePromptInfo,
+			data.BookValue[len(data.BookValue)-1],
+		)
+		if err != nil {
+			return err
+		}
+
+		perpetualRate, err := getFlagOrPromptFloat(
+			cCtx,
+			"perpetual-rate",
+			"Perpetual Growth Rate",
+			perpetualGrowthInfo,
+			defaultPerpetualRate,
+		)
+		if err != nil {
+			return err
+		}
+
+		bookValuePerShare := float64(currentBookValue) / float64(data.Shares)
+
+		expectedReturn, err := calc.ExpectedReturn(
+			growthRate,
+			roe*bookValuePerShare,
+			data.Price,
+		)
+		if err != nil {
+			return err
+		}
+
+		fairValue, projectedResidualIncome, err := calc.ResidualIncome(
+			currentBookValue,
+			roe,
+			growthRate,
+			perpetualRate,
+			fyHistory,
+			data.Shares,
+			discountRate,
+		)
+		if err != nil {
+			return err
+		}
+
+		impliedReturn, err := calc.ImpliedDiscountRate(
+			data.Price,
+			perpetualRate,
+			func(rate float64) (float64, error) {
+				fairValue, _, err := calc.ResidualIncome(
+					currentBookValue,
+					roe,
+					growthRate,
+					perpetualRate,
+					fyHistory,
+					data.Shares,
+					rate,
+				)
+				return fairValue, err
+			},
+		)
+		if err != nil {
+			// the price can be out of reach at any discount rate, which doesn't invalidate the valuation
+			impliedReturn = math.NaN()
+		}
+
+		upside, err := calc.Upside(fairValue, data.Price)
+		if err != nil {
+			return err
+		}
+
+		writer.ResidualIncome(bookValuePerShare, roe, discountRate)
+		writer.Projected(projectedResidualIncome, growthRate, expectedReturn, impliedReturn, upside)
+		writer.FairValue(fairValue)
+		writer.Render()
+		return nil
+	},
+}
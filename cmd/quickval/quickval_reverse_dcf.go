@@ -0,0 +1,166 @@
+package main
+
+import (
+	"math"
+	"os"
+
+	"github.com/shanehull/quickval/internal/calc"
+	"github.com/shanehull/quickval/internal/output"
+	"github.com/shanehull/quickval/internal/quickfs"
+	"github.com/urfave/cli/v2"
+)
+
+var reverseDCFCommand = &cli.Command{
+	Name:        "reverse-dcf",
+	Aliases:     []string{"rdcf"},
+	Description: "Solves for the growth rate that a growth-exit or two-stage DCF model needs to equal the current price.",
+	Usage:       "Performs a reverse DCF to find the market-implied growth rate.",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "model",
+			Value: "",
+			Usage: "the DCF model to solve (growth-exit or two-stage)",
+		},
+		&cli.Float64Flag{
+			Name:  "risk-free",
+			Value: 0.00,
+			Usage: "the risk-free rate in decimal format",
+		},
+		&cli.Float64Flag{
+			Name:  "risk-premium",
+			Value: 0.00,
+			Usage: "the equity risk premium rate in decimal format",
+		},
+		&cli.IntFlag{
+			Name:  "current-fcf",
+			Value: 0,
+			Usage: "override the current FCF with a normalized number",
+		},
+		&cli.StringFlag{
+			Name:  "normalize",
+			Value: "",
+			Usage: "how to normalize the current FCF (latest, mean, median, trimmed-mean, trend or margin)",
+		},
+		&cli.Float64Flag{
+			Name:  "exit-multiple",
+			Value: 0.00,
+			Usage: "exit multiple to apply to the final year FCF (growth-exit)",
+		},
+		&cli.Float64Flag{
+			Name:  "perpetual-rate",
+			Value: 0.00,
+			Usage: "perpetual growth rate of the free cash flow after the high-growth stage (two-stage)",
+		},
+		&cli.IntFlag{
+			Name:  "fy-history",
+			Value: 0,
+			Usage: "FY history to retrieve for financial reports",
+		},
+	},
+	Action: func(cCtx *cli.Context) error {
+		writer := output.NewWriter(os.Stdout)
+
+		data, fyHistory, discountRate, err := doCommonSetup(
+			cCtx,
+			writer,
+			quickfs.WithFCF(),
+			quickfs.WithRevenue(),
+		)
+		if err != nil {
+			return err
+		}
+
+		model, err := getFlagOrSelect(
+			cCtx,
+			"model",
+			"DCF Model",
+			modelPromptInfo,
+			[]string{growthExitCommand.Name, twoStageCommand.Name},
+		)
+		if err != nil {
+			return err
+		}
+
+		currentFCF, err := getFlagOrSelectCurrent(
+			cCtx,
+			"current-fcf",
+			"Current FCF",
+			fcfPromptInfo,
+			data.FCFHistory,
+			data.Revenue,
+		)
+		if err != nil {
+			return err
+		}
+
+		var valueAt func(growthRate float64) (float64, error)
+
+		switch model {
+		case growthExitCommand.Name:
+			currentMultipleFloor := math.Floor(
+				data.Price / (float64(currentFCF) / float64(data.Shares)),
+			)
+
+			exitMultiple, err := getFlagOrPromptFloat(
+				cCtx,
+				"exit-multiple",
+				"Exit Multiple",
+				exitPromptInfo,
+				currentMultipleFloor,
+			)
+			if err != nil {
+				return err
+			}
+
+			valueAt = func(growthRate float64) (float64, error) {
+				fairValue, _, err := calc.DCFGrowthExit(
+					currentFCF,
+					growthRate,
+					exitMultiple,
+					fyHistory,
+					data.Shares,
+					discountRate,
+				)
+				return fairValue, err
+			}
+		case twoStageCommand.Name:
+			perpetualRate, err := getFlagOrPromptFloat(
+				cCtx,
+				"perpetual-rate",
+				"Perpetual Growth Rate",
+				perpetualGrowthInfo,
+				defaultPerpetualRate,
+			)
+			if err != nil {
+				return err
+			}
+
+			valueAt = func(growthRate float64) (float64, error) {
+				fairValue, _, err := calc.DCFTwoStage(
+					currentFCF,
+					growthRate,
+					perpetualRate,
+					fyHistory,
+					data.Shares,
+					discountRate,
+				)
+				return fairValue, err
+			}
+		default:
+			return cli.Exit("unsupported model option", 127)
+		}
+
+		impliedRate, err := calc.ImpliedGrowthRate(data.Price, valueAt)
+		if err != nil {
+			return err
+		}
+
+		historicRate, _ := calc.CAGR(data.FCFHistory)
+
+		writer.ReverseDCF(model, data.Price, historicRate)
+		writer.ImpliedGrowthRate(impliedRate)
+		writer.Render()
+
+		return nil
+	},
+}
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+
+	"github.com/shanehull/quickval/internal/calc"
+	"github.com/shanehull/quickval/internal/output"
+	"github.com/shanehull/quickval/internal/quickfs"
+	"github.com/shanehull/quickval/internal/scenario"
+	"github.com/urfave/cli/v2"
+)
+
+var scenariosCommand = &cli.Command{
+	Name:    "scenarios",
+	Aliases: []string{"sc"},
+	Description: "Runs a growth-exit or two-stage DCF model for each of a set of scenarios (e.g. bear, base and bull), " +
+		"each with its own growth rate, FCF margin, exit multiple or perpetual rate and probability, " +
+		"and weights the fair values by their probabilities. Scenarios are read from a YAML or JSON file, or prompted for.",
+	Usage: "Performs a probability-weighted scenario analysis of a DCF model.",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "model",
+			Value: "",
+			Usage: "the DCF model to run for each scenario (growth-exit or two-stage)",
+		},
+		&cli.StringFlag{
+			Name:  "scenarios",
+			Value: "",
+			Usage: "YAML or JSON file of scenarios, each with a name, probability, growthRate, margin and exitMultiple or perpetualRate",
+		},
+		&cli.Float64Flag{
+			Name:  "risk-free",
+			Value: 0.00,
+			Usage: "the risk-free rate in decimal format",
+		},
+		&cli.Float64Flag{
+			Name:  "risk-premium",
+			Value: 0.00,
+			Usage: "the equity risk premium rate in decimal format",
+		},
+		&cli.IntFlag{
+			Name:  "current-fcf",
+			Value: 0,
+			Usage: "current FCF for scenarios without a margin",
+		},
+		&cli.StringFlag{
+			Name:  "normalize",
+			Value: "",
+			Usage: "how to normalize the current FCF (latest, mean, median, trimmed-mean, trend or margin)",
+		},
+		&cli.IntFlag{
+			Name:  "fy-history",
+			Value: 0,
+			Usage: "FY history to retrieve for financial reports",
+		},
+	},
+	Action: func(cCtx *cli.Context) error {
+		writer := output.NewWriter(os.Stdout)
+
+		data, fyHistory, discountRate, err := doCommonSetup(
+			cCtx,
+			writer,
+			quickfs.WithFCF(),
+			quickfs.WithRevenue(),
+		)
+		if err != nil {
+			return err
+		}
+
+		model, err := getFlagOrSelect(
+			cCtx,
+			"model",
+			"DCF Model",
+			modelPromptInfo,
+			[]string{growthExitCommand.Name, twoStageCommand.Name},
+		)
+		if err != nil {
+			return err
+		}
+
+		var scenarios []scenario.Scenario
+		if path := cCtx.String("scenarios"); path != "" {
+			terminalInput := scenario.PerpetualRate
+			if model == growthExitCommand.Name {
+				terminalInput = scenario.ExitMultiple
+			}
+			scenarios, err = scenario.Load(path, terminalInput)
+		} else {
+			scenarios, err = promptScenarios(&data, model)
+		}
+		if err != nil {
+			return err
+		}
+
+		// scenarios without a margin start from the current FCF, only prompt for it if it's needed
+		currentFCF := 0
+		for _, s := range scenarios {
+			if s.Margin == 0 {
+				currentFCF, err = getFlagOrSelectCurrent(
+					cCtx,
+					"current-fcf",
+					"Current FCF",
+					fcfPromptInfo,
+					data.FCFHistory,
+					data.Revenue,
+				)
+				if err != nil {
+					return err
+				}
+				break
+			}
+		}
+
+		var (
+			fairValues    []float64
+			probabilities []float64
+		)
+
+		for _, s := range scenarios {
+			startingFCF := currentFCF
+			if s.Margin != 0 {
+				if len(data.Revenue) == 0 {
+					return fmt.Errorf("no revenue history to apply the %s scenario's margin to", s.Name)
+				}
+				startingFCF = int(float64(data.Revenue[len(data.Revenue)-1]) * s.Margin)
+			}
+
+			var (
+				fairValue     float64
+				terminal      float64
+				terminalLabel string
+			)
+
+			switch model {
+			case growthExitCommand.Name:
+				terminal, terminalLabel = s.ExitMultiple, "Exit Multiple"
+				fairValue, _, err = calc.DCFGrowthExit(
+					startingFCF,
+					s.GrowthRate,
+					s.ExitMultiple,
+					fyHistory,
+					data.Shares,
+					discountRate,
+				)
+			case twoStageCommand.Name:
+				terminal, terminalLabel = s.PerpetualRate, "Perpetual Growth Rate"
+				fairValue, _, err = calc.DCFTwoStage(
+					startingFCF,
+					s.GrowthRate,
+					s.PerpetualRate,
+					fyHistory,
+					data.Shares,
+					discountRate,
+				)
+			default:
+				return cli.Exit("unsupported model option", 127)
+			}
+			if err != nil {
+				return fmt.Errorf("%s scenario: %w", s.Name, err)
+			}
+
+			writer.Scenario(s, startingFCF, terminalLabel, terminal, fairValue)
+
+			fairValues = append(fairValues, fairValue)
+			probabilities = append(probabilities, s.Probability)
+		}
+
+		weighted, err := calc.ProbabilityWeighted(fairValues, probabilities)
+		if err != nil {
+			return err
+		}
+
+		upside, err := calc.Upside(weighted, data.Price)
+		if err != nil {
+			return err
+		}
+
+		writer.ProbabilityWeighted(weighted, data.Price, upside)
+		writer.FairValue(weighted)
+		writer.Render()
+
+		return nil
+	},
+}
+
+// promptScenarios prompts for the inputs of a bear, base and bull scenario.
+// The defaults spread the historic growth rate around the base case, at the current FCF margin.
+func promptScenarios(data *quickfs.Data, model string) ([]scenario.Scenario, error) {
+	growthRate, err := calc.CAGR(data.FCFHistory)
+	if err != nil {
+		growthRate = 0
+	}
+
+	var margin float64
+	if len(data.Revenue) > 0 && len(data.FCFHistory) > 0 {
+		latestRevenue := data.Revenue[len(data.Revenue)-1]
+		if latestRevenue != 0 {
+			margin = float64(data.FCFHistory[len(data.FCFHistory)-1]) / float64(latestRevenue)
+		}
+	}
+
+	cases := []struct {
+		name        string
+		probability float64
+		growthShift float64
+	}{
+		{"Bear", 0.25, -scenarioGrowthSpread},
+		{"Base", 0.5, 0},
+		{"Bull", 0.25, scenarioGrowthSpread},
+	}
+
+	printTip(scenarioPromptInfo)
+
+	var scenarios []scenario.Scenario
+	for _, c := range cases {
+		s := scenario.Scenario{Name: c.name}
+
+		s.Probability, err = promptFloat(c.name+" Probability", c.probability, "")
+		if err != nil {
+			return nil, err
+		}
+
+		s.GrowthRate, err = promptFloat(c.name+" Growth Rate", growthRate+c.growthShift, "")
+		if err != nil {
+			return nil, err
+		}
+
+		s.Margin, err = promptFloat(c.name+" FCF Margin", margin, "")
+		if err != nil {
+			return nil, err
+		}
+
+		switch model {
+		case growthExitCommand.Name:
+			latestFCF := data.FCFHistory[len(data.FCFHistory)-1]
+			s.ExitMultiple, err = promptFloat(
+				c.name+" Exit Multiple",
+				math.Floor(data.Price/(float64(latestFCF)/float64(data.Shares))),
+				"",
+			)
+		default:
+			s.PerpetualRate, err = promptFloat(
+				c.name+" Perpetual Growth Rate",
+				defaultPerpetualRate,
+				"",
+			)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		scenarios = append(scenarios, s)
+	}
+
+	return scenarios, nil
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"errors"
+	"math"
+	"os"
+
+	"github.com/shanehull/quickval/internal/calc"
+	"github.com/shanehull/quickval/internal/output"
+	"github.com/shanehull/quickval/internal/quickfs"
+	"github.com/urfave/cli/v2"
+)
+
+var shareholderYieldCommand = &cli.Command{
+	Name:    "shareholder-yield",
+	Aliases: []string{"sy"},
+	Description: "Performs a two-stage discount model of the total cash returned to shareholders, i.e. dividends plus share " +
+		"repurchases net of issuance, for companies that return most of their cash via buybacks.",
+	Usage: "Performs a two-stage total shareholder yield model.",
+	Flags: []cli.Flag{
+		&cli.Float64Flag{
+			Name:  "risk-free",
+			Value: 0.00,
+			Usage: "the risk free rate in decimal format",
+		},
+		&cli.Float64Flag{
+			Name:  "risk-premium",
+			Value: 0.00,
+			Usage: "the equity risk premium rate in decimal format",
+		},
+		&cli.IntFlag{
+			Name:  "current-payout",
+			Value: 0,
+			Usage: "current cash returned to shareholders (dividends plus net repurchases) of the company",
+		},
+		&cli.StringFlag{
+			Name:  "normalize",
+			Value: "",
+			Usage: "how to normalize the current payout (latest, mean, median, trimmed-mean or trend)",
+		},
+		&cli.Float64Flag{
+			Name:  "growth-rate",
+			Value: 0.00,
+			Usage: "annual growth rate of the shareholder payout during the high-growth stage",
+		},
+		&cli.StringFlag{
+			Name:  "growth-method",
+			Value: "",
+			Usage: "how to estimate the default growth rate (cagr, log-linear or median-yoy)",
+		},
+		&cli.Float64Flag{
+			Name:  "perpetual-rate",
+			Value: 0.00,
+			Usage: "perpetual growth rate of the shareholder payout after the high-growth stage",
+		},
+		&cli.IntFlag{
+			Name:  "fy-history",
+			Value: 0,
+			Usage: "FY history to retrieve for financial reports",
+		},
+		&cli.BoolFlag{
+			Name:  "mid-year",
+			Value: false,
+			Usage: "discount cash flows from the middle of each year rather than the end",
+		},
+		&cli.StringFlag{
+			Name:  "fy-end",
+			Value: "",
+			Usage: "end date (YYYY-MM-DD) of the latest fiscal year, to value from a partial first year (stub period)",
+		},
+		&cli.StringFlag{
+			Name:  "valuation-date",
+			Value: "",
+			Usage: "date (YYYY-MM-DD) of the valuation when using --fy-end (defaults to today)",
+		},
+		&cli.StringFlag{
+			Name:  "discount-curve",
+			Value: "",
+			Usage: "comma separated discount rates for each projected year, or a file of them, e.g. 0.12,0.11,0.10 (the discount rate applies after the curve and to the terminal value)",
+		},
+	},
+	Action: func(cCtx *cli.Context) error {
+		writer := output.NewWriter(os.Stdout)
+
+		data, fyHistory, discountRate, err := doEquitySetup(
+			cCtx,
+			writer,
+			quickfs.WithCFFDividends(),
+			quickfs.WithBuybacks(),
+		)
+		if err != nil {
+			return err
+		}
+
+		payouts, err := calc.ShareholderPayouts(
+			data.CFFDividends,
+			data.CFFRepurchased,
+			data.CFFIssued,
+		)
+		if err != nil {
+			return err
+		}
+		if len(payouts) < 1 {
+			return errors.New("no dividend or buyback history")
+		}
+
+		yields, err := calc.ShareholderYields(
+			data.CFFDividends,
+			data.CFFRepurchased,
+			data.CFFIssued,
+			data.Price*float64(data.Shares),
+		)
+		if err != nil {
+			return err
+		}
+
+		modelOpts, err := discountingOpts(cCtx, writer, discountRate)
+		if err != nil {
+			return err
+		}
+
+		var breakdown calc.Breakdown
+
+		growthRate, err := getFlagOrPromptGrowthRate(
+			cCtx,
+			"growth-rate",
+			"Growth Rate",
+			growthPromptInfo,
+			payouts,
+		)
+		if err != nil {
+			return err
+		}
+
+		currentPayout, err := getFlagOrSelectCurrent(
+			cCtx,
+			"current-payout",
+			"Current Shareholder Payout",
+			payoutPromptInfo,
+			payouts,
+			nil,
+		)
+		if err != nil {
+			return err
+		}
+		if currentPayout <= 0 {
+			return errors.New(
+				"the current shareholder payout is not positive, issuance exceeds the cash returned",
+			)
+		}
+
+		perpetualRate, err := getFlagOrPromptFloat(
+			cCtx,
+			"perpetual-rate",
+			"Perpetual Growth Rate",
+			perpetualGrowthInfo,
+			defaultPerpetualRate,
+		)
+		if err != nil {
+			return err
+		}
+
+		expectedReturn, err := calc.ExpectedReturn(
+			growthRate,
+			float64(currentPayout)/float64(data.Shares),
+			data.Price,
+		)
+		if err != nil {
+			return err
+		}
+
+		// the payout goes to the current shareholders, net buybacks shrink the share count rather than the value
+		fairValue, projectedPayouts, err := calc.DDMTwoStage(
+			currentPayout,
+			growthRate,
+			perpetualRate,
+			fyHistory,
+			data.Shares,
+			discountRate,
+			append(modelOpts, calc.WithBreakdown(&breakdown))...,
+		)
+		if err != nil {
+			return err
+		}
+
+		impliedReturn, err := calc.ImpliedDiscountRate(
+			data.Price,
+			perpetualRate,
+			func(rate float64) (float64, error) {
+				fairValue, _, err := calc.DDMTwoStage(
+					currentPayout,
+					growthRate,
+					perpetualRate,
+					fyHistory,
+					data.Shares,
+					rate,
+					modelOpts...,
+				)
+				return fairValue, err
+			},
+		)
+		if err != nil {
+			// the price can be out of reach at any discount rate, which doesn't invalidate the valuation
+			impliedReturn = math.NaN()
+		}
+
+		upside, err := calc.Upside(fairValue, data.Price)
+		if err != nil {
+			return err
+		}
+
+		writer.ShareholderYield(yields)
+		writer.Projected(projectedPayouts, growthRate, expectedReturn, impliedReturn, upside)
+
+		if cCtx.String("discount-curve") != "" {
+			writer.DiscountCurve(breakdown)
+		}
+
+		writer.FairValue(fairValue)
+		writer.Render()
+		return nil
+	},
+}
@@ -0,0 +1,257 @@
+package main
+
+import (
+	"errors"
+	"math"
+	"os"
+
+	"github.com/shanehull/quickval/internal/calc"
+	"github.com/shanehull/quickval/internal/output"
+	"github.com/shanehull/quickval/internal/quickfs"
+	"github.com/urfave/cli/v2"
+)
+
+var storyCommand = &cli.Command{
+	Name:    "story",
+	Aliases: []string{"dcfs"},
+	Description: "Performs a revenue-driven DCF model, where each year's FCF is derived from revenue growth, " +
+		"an operating margin that converges to a target, and the reinvestment needed to grow at a sales-to-capital ratio.",
+	Usage: "Performs a revenue-driven (story) DCF model.",
+	Flags: []cli.Flag{
+		&cli.Float64Flag{
+			Name:  "risk-free",
+			Value: 0.00,
+			Usage: "the risk free rate in decimal format",
+		},
+		&cli.Float64Flag{
+			Name:  "risk-premium",
+			Value: 0.00,
+			Usage: "the equity risk premium rate in decimal format",
+		},
+		&cli.IntFlag{
+			Name:  "current-revenue",
+			Value: 0,
+			Usage: "current revenue of the company",
+		},
+		&cli.Float64Flag{
+			Name:  "revenue-growth",
+			Value: 0.00,
+			Usage: "annual growth rate of the revenue during the projection",
+		},
+		&cli.StringFlag{
+			Name:  "growth-method",
+			Value: "",
+			Usage: "how to estimate the default revenue growth rate (cagr, log-linear or median-yoy)",
+		},
+		&cli.Float64Flag{
+			Name:  "current-margin",
+			Value: 0.00,
+			Usage: "current operating (EBIT) margin",
+		},
+		&cli.Float64Flag{
+			Name:  "target-margin",
+			Value: 0.00,
+			Usage: "operating margin the company converges to by the final projected year",
+		},
+		&cli.Float64Flag{
+			Name:  "sales-to-capital",
+			Value: 0.00,
+			Usage: "revenue added for each unit of capital reinvested",
+		},
+		&cli.Float64Flag{
+			Name:  "perpetual-rate",
+			Value: 0.00,
+			Usage: "perpetual growth rate of the revenue after the projection",
+		},
+		&cli.IntFlag{
+			Name:  "fy-history",
+			Value: 0,
+			Usage: "FY history to retrieve for financial reports",
+		},
+		&cli.BoolFlag{
+			Name:  "mid-year",
+			Value: false,
+			Usage: "discount cash flows from the middle of each year rather than the end",
+		},
+		&cli.StringFlag{
+			Name:  "fy-end",
+			Value: "",
+			Usage: "end date (YYYY-MM-DD) of the latest fiscal year, to value from a partial first year (stub period)",
+		},
+		&cli.StringFlag{
+			Name:  "valuation-date",
+			Value: "",
+			Usage: "date (YYYY-MM-DD) of the valuation when using --fy-end (defaults to today)",
+		},
+		&cli.StringFlag{
+			Name:  "discount-curve",
+			Value: "",
+			Usage: "comma separated discount rates for each projected year, or a file of them, e.g. 0.12,0.11,0.10 (the discount rate applies after the curve and to the terminal value)",
+		},
+	},
+	Action: func(cCtx *cli.Context) error {
+		writer := output.NewWriter(os.Stdout)
+
+		data, fyHistory, discountRate, err := doEnterpriseSetup(
+			cCtx,
+			writer,
+			quickfs.WithRevenue(),
+			quickfs.WithEBIT(),
+			quickfs.WithOperatingMargin(),
+			quickfs.WithInvestedCapital(),
+			quickfs.WithBalanceSheet(),
+		)
+		if err != nil {
+			return err
+		}
+
+		if len(data.Revenue) < 1 {
+			return errors.New("no revenue history")
+		}
+
+		// the projected FCF is unlevered, so the model values the enterprise
+		var breakdown calc.Breakdown
+		bridge := equityBridge(&data)
+
+		modelOpts, err := discountingOpts(cCtx, writer, discountRate)
+		if err != nil {
+			return err
+		}
+		modelOpts = append(modelOpts, calc.WithEquityBridge(bridge))
+
+		inputs := calc.StoryInputs{TaxRate: data.TaxRate}
+
+		inputs.CurrentRevenue, err = getFlagOrPromptInt(
+			cCtx,
+			"current-revenue",
+			"Current Revenue",
+			revenuePromptInfo,
+			data.Revenue[len(data.Revenue)-1],
+		)
+		if err != nil {
+			return err
+		}
+
+		inputs.RevenueGrowth, err = getFlagOrPromptGrowthRate(
+			cCtx,
+			"revenue-growth",
+			"Revenue Growth Rate",
+			growthPromptInfo,
+			data.Revenue,
+		)
+		if err != nil {
+			return err
+		}
+
+		// fall back to the margin from EBIT, in case the margin isn't reported
+		currentMargin := 0.0
+		margins := data.OperatingMargin
+		if len(margins) == 0 {
+			margins, _ = calc.Margins(data.EBIT, data.Revenue)
+		}
+		if len(margins) > 0 {
+			currentMargin = margins[len(margins)-1]
+		}
+
+		inputs.CurrentMargin, err = getFlagOrPromptFloat(
+			cCtx,
+			"current-margin",
+			"Current Operating Margin",
+			marginPromptInfo,
+			currentMargin,
+		)
+		if err != nil {
+			return err
+		}
+
+		inputs.TargetMargin, err = getFlagOrPromptFloat(
+			cCtx,
+			"target-margin",
+			"Target Operating Margin",
+			targetMarginInfo,
+			calc.Mean(margins),
+		)
+		if err != nil {
+			return err
+		}
+
+		salesToCapital, err := calc.SalesToCapital(data.Revenue, data.InvestedCapital)
+		if err != nil {
+			salesToCapital = 0
+		}
+
+		inputs.SalesToCapital, err = getFlagOrPromptFloat(
+			cCtx,
+			"sales-to-capital",
+			"Sales-to-Capital",
+			salesToCapitalInfo,
+			salesToCapital,
+		)
+		if err != nil {
+			return err
+		}
+
+		inputs.PerpetualGrowthRate, err = getFlagOrPromptFloat(
+			cCtx,
+			"perpetual-rate",
+			"Perpetual Growth Rate",
+			perpetualGrowthInfo,
+			defaultPerpetualRate,
+		)
+		if err != nil {
+			return err
+		}
+
+		fairValue, projections, err := calc.DCFStory(
+			inputs,
+			fyHistory,
+			data.Shares,
+			discountRate,
+			append(modelOpts, calc.WithBreakdown(&breakdown))...,
+		)
+		if err != nil {
+			return err
+		}
+
+		impliedReturn, err := calc.ImpliedDiscountRate(
+			data.Price,
+			inputs.PerpetualGrowthRate,
+			func(rate float64) (float64, error) {
+				fairValue, _, err := calc.DCFStory(
+					inputs,
+					fyHistory,
+					data.Shares,
+					rate,
+					modelOpts...,
+				)
+				return fairValue, err
+			},
+		)
+		if err != nil {
+			// the price can be out of reach at any discount rate, which doesn't invalidate the valuation
+			impliedReturn = math.NaN()
+		}
+
+		upside, err := calc.Upside(fairValue, data.Price)
+		if err != nil {
+			return err
+		}
+
+		warnings, err := terminalWarnings(cCtx, breakdown)
+		if err != nil {
+			return err
+		}
+
+		writer.EquityBridge(breakdown, bridge, data.Shares)
+		writer.StoryProjected(projections, inputs, impliedReturn, upside)
+		writer.TerminalValue(breakdown, warnings)
+
+		if cCtx.String("discount-curve") != "" {
+			writer.DiscountCurve(breakdown)
+		}
+
+		writer.FairValue(fairValue)
+		writer.Render()
+		return nil
+	},
+}
@@ -0,0 +1,278 @@
+package main
+
+import (
+	"math"
+	"os"
+
+	"github.com/shanehull/quickval/internal/calc"
+	"github.com/shanehull/quickval/internal/output"
+	"github.com/urfave/cli/v2"
+)
+
+var threeStageCommand = &cli.Command{
+	Name:        "three-stage",
+	Aliases:     []string{"dcf3"},
+	Description: "Performs a three-stage DCF model with a high-growth stage, a transition stage where growth fades linearly to the perpetual rate, and a perpetual growth stage.",
+	Usage:       "Performs a three-stage DCF model.",
+	Flags: []cli.Flag{
+		&cli.Float64Flag{
+			Name:  "risk-free",
+			Value: 0.00,
+			Usage: "the risk free rate in decimal format",
+		},
+		&cli.Float64Flag{
+			Name:  "risk-premium",
+			Value: 0.00,
+			Usage: "the equity risk premium rate in decimal format",
+		},
+		&cli.IntFlag{
+			Name:  "current-fcf",
+			Value: 0,
+			Usage: "current free cash flow of the company",
+		},
+		&cli.StringFlag{
+			Name:  "normalize",
+			Value: "",
+			Usage: "how to normalize the current FCF (latest, mean, median, trimmed-mean, trend or margin)",
+		},
+		&cli.Float64Flag{
+			Name:  "growth-rate",
+			Value: 0.00,
+			Usage: "annual growth rate of the free cash flow during the high-growth stage",
+		},
+		&cli.StringFlag{
+			Name:  "growth-method",
+			Value: "",
+			Usage: "how to estimate the default growth rate (cagr, log-linear, median-yoy or fundamental)",
+		},
+		&cli.BoolFlag{
+			Name:  "per-share",
+			Value: false,
+			Usage: "estimate growth on FCF per share, using the diluted share history",
+		},
+		&cli.Float64Flag{
+			Name:  "share-change",
+			Value: 0.00,
+			Usage: "projected annual dilution of the share count, e.g. 0.02 for net issuance of 2% a year (buybacks are already paid for from the FCF)",
+		},
+		&cli.Float64Flag{
+			Name:  "perpetual-rate",
+			Value: 0.00,
+			Usage: "perpetual growth rate of the free cash flow after the transition stage",
+		},
+		&cli.IntFlag{
+			Name:  "transition-years",
+			Value: 0,
+			Usage: "number of years in the transition stage",
+		},
+		&cli.IntFlag{
+			Name:  "fy-history",
+			Value: 0,
+			Usage: "FY history to retrieve for financial reports",
+		},
+		&cli.BoolFlag{
+			Name:  "unlevered",
+			Value: false,
+			Usage: "value unlevered FCF (FCFF) as an enterprise value, then deduct net debt, minority interest and preferred equity",
+		},
+		&cli.BoolFlag{
+			Name:  "mid-year",
+			Value: false,
+			Usage: "discount cash flows from the middle of each year rather than the end",
+		},
+		&cli.StringFlag{
+			Name:  "fy-end",
+			Value: "",
+			Usage: "end date (YYYY-MM-DD) of the latest fiscal year, to value from a partial first year (stub period)",
+		},
+		&cli.StringFlag{
+			Name:  "valuation-date",
+			Value: "",
+			Usage: "date (YYYY-MM-DD) of the valuation when using --fy-end (defaults to today)",
+		},
+		&cli.StringFlag{
+			Name:  "discount-curve",
+			Value: "",
+			Usage: "comma separated discount rates for each projected year, or a file of them, e.g. 0.12,0.11,0.10 (the discount rate applies after the curve and to the terminal value)",
+		},
+		&cli.BoolFlag{
+			Name:  "sensitivity",
+			Value: false,
+			Usage: "render fair value sensitivity matrices",
+		},
+		&cli.Float64Flag{
+			Name:  "sensitivity-step",
+			Value: 0.01,
+			Usage: "the step between discount and growth rates in the sensitivity matrices",
+		},
+	},
+	Action: func(cCtx *cli.Context) error {
+		writer := output.NewWriter(os.Stdout)
+
+		setup, err := doDCFSetup(cCtx, writer)
+		if err != nil {
+			return err
+		}
+
+		var (
+			data         = setup.data
+			fyHistory    = setup.fyHistory
+			discountRate = setup.discountRate
+			bridge       = setup.bridge
+			modelOpts    = setup.modelOpts
+			breakdown    calc.Breakdown
+		)
+
+		history, err := growthHistory(cCtx, data.FCFHistory, &data)
+		if err != nil {
+			return err
+		}
+
+		growthRate, err := getFlagOrPromptGrowthRate(
+			cCtx,
+			"growth-rate",
+			"Growth Rate",
+			growthPromptInfo,
+			history,
+			fundamentalGrowthEstimate(cCtx, &data)...,
+		)
+		if err != nil {
+			return err
+		}
+
+		currentFCF, err := getFlagOrSelectCurrent(
+			cCtx,
+			"current-fcf",
+			"Current FCF",
+			fcfPromptInfo,
+			data.FCFHistory,
+			data.Revenue,
+		)
+		if err != nil {
+			return err
+		}
+
+		transitionYears, err := getFlagOrPromptInt(
+			cCtx,
+			"transition-years",
+			"Transition Years",
+			transitionYearsInfo,
+			defaultTransitionYears,
+		)
+		if err != nil {
+			return err
+		}
+
+		perpetualRate, err := getFlagOrPromptFloat(
+			cCtx,
+			"perpetual-rate",
+			"Perpetual Growth Rate",
+			perpetualGrowthInfo,
+			defaultPerpetualRate,
+		)
+		if err != nil {
+			return err
+		}
+
+		// compounding a negative FCF is meaningless, it needs a turnaround projection
+		if currentFCF <= 0 {
+			return cli.Exit("the current FCF is not positive, use the turnaround command", 127)
+		}
+
+		expectedReturn, err := calc.ExpectedReturn(
+			growthRate,
+			float64(currentFCF)/float64(data.Shares),
+			data.Price,
+		)
+		if err != nil {
+			return err
+		}
+
+		fairValue, projectedFCF, err := calc.DCFThreeStage(
+			currentFCF,
+			growthRate,
+			perpetualRate,
+			fyHistory,
+			transitionYears,
+			data.Shares,
+			discountRate,
+			append(modelOpts, calc.WithBreakdown(&breakdown))...,
+		)
+		if err != nil {
+			return err
+		}
+
+		impliedReturn, err := calc.ImpliedDiscountRate(
+			data.Price,
+			perpetualRate,
+			func(rate float64) (float64, error) {
+				fairValue, _, err := calc.DCFThreeStage(
+					currentFCF,
+					growthRate,
+					perpetualRate,
+					fyHistory,
+					transitionYears,
+					data.Shares,
+					rate,
+					modelOpts...,
+				)
+				return fairValue, err
+			},
+		)
+		if err != nil {
+			// the price can be out of reach at any discount rate, which doesn't invalidate the valuation
+			impliedReturn = math.NaN()
+		}
+
+		upside, err := calc.Upside(fairValue, data.Price)
+		if err != nil {
+			return err
+		}
+
+		if cCtx.Bool("unlevered") {
+			writer.EquityBridge(breakdown, bridge, data.Shares)
+		}
+
+		warnings, err := terminalWarnings(cCtx, breakdown)
+		if err != nil {
+			return err
+		}
+
+		writer.Projected(projectedFCF, growthRate, expectedReturn, impliedReturn, upside)
+		writer.TerminalValue(breakdown, warnings)
+
+		if cCtx.String("discount-curve") != "" {
+			writer.DiscountCurve(breakdown)
+		}
+
+		if cCtx.Bool("sensitivity") {
+			writeSensitivity(
+				writer,
+				cCtx.Float64("sensitivity-step"),
+				discountRate,
+				growthRate,
+				perpetualRate,
+				"Perpetual Rate",
+				"%.3f",
+				cCtx.Float64("sensitivity-step")/2,
+				func(rate, growth, terminal float64) (float64, error) {
+					fairValue, _, err := calc.DCFThreeStage(
+						currentFCF,
+						growth,
+						terminal,
+						fyHistory,
+						transitionYears,
+						data.Shares,
+						rate,
+						modelOpts...,
+					)
+					return fairValue, err
+				},
+			)
+		}
+
+		writer.FairValue(fairValue)
+		writer.Render()
+		return nil
+	},
+}
@@ -0,0 +1,266 @@
+package main
+
+import (
+	"errors"
+	"math"
+	"os"
+
+	"github.com/shanehull/quickval/internal/calc"
+	"github.com/shanehull/quickval/internal/output"
+	"github.com/shanehull/quickval/internal/quickfs"
+	"github.com/urfave/cli/v2"
+)
+
+var turnaroundCommand = &cli.Command{
+	Name:    "turnaround",
+	Aliases: []string{"dcft"},
+	Description: "Performs a DCF model for a company with negative FCF, where the cash burn narrows until FCF turns positive " +
+		"in a turnaround year, then grows to a target FCF (or a target margin on the projected revenue). " +
+		"The burn beyond the cash on hand can be funded by issuing shares, and the value is weighted by the probability of failure.",
+	Usage: "Performs a turnaround DCF model for a company with negative FCF.",
+	Flags: []cli.Flag{
+		&cli.Float64Flag{
+			Name:  "risk-free",
+			Value: 0.00,
+			Usage: "the risk free rate in decimal format",
+		},
+		&cli.Float64Flag{
+			Name:  "risk-premium",
+			Value: 0.00,
+			Usage: "the equity risk premium rate in decimal format",
+		},
+		&cli.IntFlag{
+			Name:  "current-fcf",
+			Value: 0,
+			Usage: "current (negative) free cash flow of the company",
+		},
+		&cli.StringFlag{
+			Name:  "normalize",
+			Value: "",
+			Usage: "how to normalize the current FCF (latest, mean, median, trimmed-mean, trend or margin)",
+		},
+		&cli.IntFlag{
+			Name:  "turnaround-year",
+			Value: 0,
+			Usage: "first projected year of positive FCF",
+		},
+		&cli.IntFlag{
+			Name:  "target-fcf",
+			Value: 0,
+			Usage: "FCF reached in the final projected year (defaults to a target margin on the projected revenue)",
+		},
+		&cli.Float64Flag{
+			Name:  "target-margin",
+			Value: 0.00,
+			Usage: "FCF margin reached in the final projected year, when --target-fcf is not set",
+		},
+		&cli.Float64Flag{
+			Name:  "revenue-growth",
+			Value: 0.00,
+			Usage: "annual growth rate of the revenue, to project the revenue for the target margin",
+		},
+		&cli.StringFlag{
+			Name:  "growth-method",
+			Value: "",
+			Usage: "how to estimate the default revenue growth rate (cagr, log-linear or median-yoy)",
+		},
+		&cli.Float64Flag{
+			Name:  "perpetual-rate",
+			Value: 0.00,
+			Usage: "perpetual growth rate of the free cash flow after the projection",
+		},
+		&cli.BoolFlag{
+			Name:  "dilute",
+			Value: false,
+			Usage: "fund the cash burn beyond the cash on hand by issuing shares",
+		},
+		&cli.Float64Flag{
+			Name:  "issue-price",
+			Value: 0.00,
+			Usage: "price at which shares are issued with --dilute (defaults to the current price)",
+		},
+		&cli.Float64Flag{
+			Name:  "failure-probability",
+			Value: 0.00,
+			Usage: "probability that the company fails before it turns around",
+		},
+		&cli.Float64Flag{
+			Name:  "distress-value",
+			Value: 0.00,
+			Usage: "value per share if the company fails, e.g. its liquidation value",
+		},
+		&cli.IntFlag{
+			Name:  "fy-history",
+			Value: 0,
+			Usage: "FY history to retrieve for financial reports",
+		},
+		&cli.BoolFlag{
+			Name:  "mid-year",
+			Value: false,
+			Usage: "discount cash flows from the middle of each year rather than the end",
+		},
+		&cli.StringFlag{
+			Name:  "fy-end",
+			Value: "",
+			Usage: "end date (YYYY-MM-DD) of the latest fiscal year, to value from a partial first year (stub period)",
+		},
+		&cli.StringFlag{
+			Name:  "valuation-date",
+			Value: "",
+			Usage: "date (YYYY-MM-DD) of the valuation when using --fy-end (defaults to today)",
+		},
+	},
+	Action: func(cCtx *cli.Context) error {
+		writer := output.NewWriter(os.Stdout)
+
+		data, fyHistory, discountRate, err := doCommonSetup(
+			cCtx,
+			writer,
+			quickfs.WithFCF(),
+			quickfs.WithRevenue(),
+			quickfs.WithBalanceSheet(),
+		)
+		if err != nil {
+			return err
+		}
+
+		modelOpts, err := discountingOpts(cCtx, writer, discountRate)
+		if err != nil {
+			return err
+		}
+
+		inputs := calc.TurnaroundInputs{
+			Cash:          data.Cash,
+			DistressValue: cCtx.Float64("distress-value"),
+		}
+
+		inputs.CurrentFCF, err = getFlagOrSelectCurrent(
+			cCtx,
+			"current-fcf",
+			"Current FCF",
+			fcfPromptInfo,
+			data.FCFHistory,
+			data.Revenue,
+		)
+		if err != nil {
+			return err
+		}
+
+		inputs.TurnaroundYear, err = getFlagOrPromptInt(
+			cCtx,
+			"turnaround-year",
+			"Turnaround Year",
+			turnaroundYearInfo,
+			min(defaultTurnaroundYear, fyHistory),
+		)
+		if err != nil {
+			return err
+		}
+
+		inputs.TargetFCF = cCtx.Int("target-fcf")
+		if inputs.TargetFCF == 0 {
+			if len(data.Revenue) < 1 {
+				return errors.New("no revenue history for a target margin, set --target-fcf")
+			}
+
+			targetMargin, err := getFlagOrPromptFloat(
+				cCtx,
+				"target-margin",
+				"Target FCF Margin",
+				targetFCFMarginInfo,
+				defaultTargetMargin,
+			)
+			if err != nil {
+				return err
+			}
+
+			revenueGrowth, err := getFlagOrPromptGrowthRate(
+				cCtx,
+				"revenue-growth",
+				"Revenue Growth Rate",
+				growthPromptInfo,
+				data.Revenue,
+			)
+			if err != nil {
+				return err
+			}
+
+			finalRevenue := float64(data.Revenue[len(data.Revenue)-1]) *
+				math.Pow(1+revenueGrowth, float64(fyHistory))
+			inputs.TargetFCF = int(finalRevenue * targetMargin)
+		}
+
+		inputs.PerpetualGrowthRate, err = getFlagOrPromptFloat(
+			cCtx,
+			"perpetual-rate",
+			"Perpetual Growth Rate",
+			perpetualGrowthInfo,
+			defaultPerpetualRate,
+		)
+		if err != nil {
+			return err
+		}
+
+		inputs.FailureProbability, err = getFlagOrPromptFloat(
+			cCtx,
+			"failure-probability",
+			"Probability of Failure",
+			failurePromptInfo,
+			defaultFailureProb,
+		)
+		if err != nil {
+			return err
+		}
+
+		if cCtx.Bool("dilute") {
+			inputs.IssuePrice = cCtx.Float64("issue-price")
+			if inputs.IssuePrice == 0.00 {
+				inputs.IssuePrice = data.Price
+			}
+		}
+
+		result, err := calc.DCFTurnaround(
+			inputs,
+			fyHistory,
+			data.Shares,
+			discountRate,
+			modelOpts...,
+		)
+		if err != nil {
+			return err
+		}
+
+		impliedReturn, err := calc.ImpliedDiscountRate(
+			data.Price,
+			inputs.PerpetualGrowthRate,
+			func(rate float64) (float64, error) {
+				result, err := calc.DCFTurnaround(
+					inputs,
+					fyHistory,
+					data.Shares,
+					rate,
+					modelOpts...,
+				)
+				return result.FairValue, err
+			},
+		)
+		if err != nil {
+			// a cash-burning company may not be worth the price at any discount rate
+			impliedReturn = math.NaN()
+		}
+
+		// a company that burns more than it will ever earn loses the whole price
+		upside := -1.0
+		if result.FairValue > 0 {
+			upside, err = calc.Upside(result.FairValue, data.Price)
+			if err != nil {
+				return err
+			}
+		}
+
+		writer.Turnaround(result, inputs, impliedReturn, upside)
+		writer.FairValue(result.FairValue)
+		writer.Render()
+		return nil
+	},
+}
@@ -5,75 +5,292 @@ import (
 	"math"
 )
 
+const (
+	// bounds of the search when solving for a market-implied growth rate
+	minImpliedGrowthRate = -0.5
+	maxImpliedGrowthRate = 1.0
+
+	// upper bound of the search when solving for an implied discount rate (IRR)
+	maxImpliedDiscountRate = 1.0
+
+	solverTolerance     = 1e-9
+	solverMaxIterations = 200
+)
+
+// CostOfCapital holds the components of a weighted average cost of capital (WACC).
+type CostOfCapital struct {
+	// UnleveredBeta is the asset beta, before financial leverage. It is zero when risk is not measured by beta.
+	UnleveredBeta float64
+	// Beta is the equity beta at the target capital structure, or the measure of risk used in its place.
+	Beta               float64
+	CostOfEquity       float64
+	CostOfDebt         float64
+	AfterTaxCostOfDebt float64
+	EquityWeight       float64
+	DebtWeight         float64
+	WACC               float64
+}
+
 // WACC calculates a weighted average cost of capital (WACC) using beta as the measure of risk.
 //
 // The WACC is a calculation of a company's cost of capital in which each capital source is weighted according to its proportion of the company's capital structure. The WACC is then used to discount future cash flows to calculate the present value of a company.
 //
+// The unlevered beta is relevered at the target capital structure using the Hamada equation, so an industry beta can be used in place of the company's own.
+//
 // Arguments:
 //
-//	beta: The company's beta. Beta is a measure of a stock's volatility in relation to the market as a whole.
-//	debtToEquityRatio: The company's debt-to-equity ratio. This is a measure of the company's financial leverage.
+//	unleveredBeta: The company's or industry's unlevered (asset) beta. See UnleveredBeta to unlever an observed beta.
+//	debtToEquityRatio: The target debt-to-equity ratio. This is a measure of the company's financial leverage.
 //	taxRate: The company's effective tax rate. This is the percentage of income that the company pays in taxes.
 //	equityRiskPremium: The equity risk premium. This is the additional return that investors demand for equity investments over and above the risk-free rate.
 //	riskFreeRate: The risk-free rate. This is the return that investors can expect to earn on a risk-free investment, such as a government bond.
+//	costOfDebt: The company's pre-tax cost of debt. See CostOfDebt and SyntheticRating.
 //
 // Returns:
 //
-//	The company's WACC as a float64.
+//	The company's WACC and its components.
 func WACC(
-	beta float64,
+	unleveredBeta float64,
 	debtToEquityRatio float64,
 	taxRate float64,
 	equityRiskPremium float64,
 	riskFreeRate float64,
-) float64 {
-	unleveredBeta := beta / (1 + (1-taxRate)*debtToEquityRatio)
+	costOfDebt float64,
+) CostOfCapital {
+	coc := weightedCostOfCapital(
+		LeveredBeta(unleveredBeta, debtToEquityRatio, taxRate),
+		debtToEquityRatio,
+		taxRate,
+		equityRiskPremium,
+		riskFreeRate,
+		costOfDebt,
+	)
+	coc.UnleveredBeta = unleveredBeta
+
+	return coc
+}
+
+// LeveredBeta relevers an unlevered (asset) beta at a debt-to-equity ratio, using the Hamada equation.
+//
+// Arguments:
+//
+//	unleveredBeta: The unlevered beta.
+//	debtToEquityRatio: The debt-to-equity ratio.
+//	taxRate: The effective tax rate.
+//
+// Returns:
+//
+//	The levered (equity) beta.
+func LeveredBeta(unleveredBeta float64, debtToEquityRatio float64, taxRate float64) float64 {
+	return unleveredBeta * (1 + (1-taxRate)*debtToEquityRatio)
+}
+
+// UnleveredBeta removes the effect of financial leverage from an observed (equity) beta, using the Hamada equation.
+//
+// Arguments:
+//
+//	leveredBeta: The observed beta.
+//	debtToEquityRatio: The debt-to-equity ratio at which the beta was observed.
+//	taxRate: The effective tax rate.
+//
+// Returns:
+//
+//	The unlevered (asset) beta.
+func UnleveredBeta(leveredBeta float64, debtToEquityRatio float64, taxRate float64) float64 {
+	return leveredBeta / (1 + (1-taxRate)*debtToEquityRatio)
+}
+
+// CostOfEquity calculates a cost of equity using the capital asset pricing model (CAPM).
+//
+// Arguments:
+//
+//	beta: The company's beta. Beta is a measure of a stock's volatility in relation to the market as a whole.
+//	equityRiskPremium: The equity risk premium. This is the additional return that investors demand for equity investments over and above the risk-free rate.
+//	riskFreeRate: The risk-free rate. This is the return that investors can expect to earn on a risk-free investment, such as a government bond.
+//
+// Returns:
+//
+//	The company's cost of equity as a float64.
+func CostOfEquity(beta float64, equityRiskPremium float64, riskFreeRate float64) float64 {
+	return riskFreeRate + (beta * equityRiskPremium)
+}
+
+// CostOfDebt calculates a pre-tax cost of debt from the interest paid on it.
+//
+// Arguments:
+//
+//	interestExpense: The company's interest expense.
+//	totalDebt: The company's total debt.
+//
+// Returns:
+//
+//	The pre-tax cost of debt.
+//	An error, if any.
+func CostOfDebt(interestExpense int, totalDebt int) (float64, error) {
+	if totalDebt <= 0 {
+		return 0, fmt.Errorf("total debt must be greater than zero to calculate a cost of debt")
+	}
 
-	costOfEquity := riskFreeRate + (unleveredBeta * equityRiskPremium)
-	costOfDebt := riskFreeRate * (1 - taxRate)
+	return math.Abs(float64(interestExpense)) / float64(totalDebt), nil
+}
+
+// syntheticRatings maps a minimum interest coverage ratio to a credit rating and its default spread,
+// for large non-financial companies (after Damodaran).
+var syntheticRatings = []struct {
+	minCoverage float64
+	rating      string
+	spread      float64
+}{
+	{8.5, "AAA", 0.0059},
+	{6.5, "AA", 0.0078},
+	{5.5, "A+", 0.0098},
+	{4.25, "A", 0.0108},
+	{3, "A-", 0.0122},
+	{2.5, "BBB", 0.0156},
+	{2.25, "BB+", 0.0200},
+	{2, "BB", 0.0240},
+	{1.75, "B+", 0.0291},
+	{1.5, "B", 0.0357},
+	{1.25, "B-", 0.0594},
+	{0.8, "CCC", 0.0946},
+	{0.65, "CC", 0.1240},
+	{0.2, "C", 0.1600},
+	{math.Inf(-1), "D", 0.2000},
+}
+
+// SyntheticRating estimates a credit rating and default spread from the interest coverage ratio, for companies without rated or traded debt.
+// The pre-tax cost of debt is the risk-free rate plus the default spread.
+//
+// Arguments:
+//
+//	ebit: The company's EBIT.
+//	interestExpense: The company's interest expense.
+//
+// Returns:
+//
+//	The synthetic rating, e.g. "A+".
+//	The default spread over the risk-free rate.
+func SyntheticRating(ebit int, interestExpense int) (string, float64) {
+	coverage := math.Inf(1)
+	if interestExpense != 0 {
+		coverage = float64(ebit) / math.Abs(float64(interestExpense))
+	}
 
-	equityWeight := 1 / (1 + debtToEquityRatio)
-	debtWeight := 1 - equityWeight
+	for _, r := range syntheticRatings {
+		if coverage >= r.minCoverage {
+			return r.rating, r.spread
+		}
+	}
 
-	wacc := (equityWeight * costOfEquity) + (debtWeight * costOfDebt * (1 - taxRate))
+	last := syntheticRatings[len(syntheticRatings)-1]
 
-	return wacc
+	return last.rating, last.spread
 }
 
 // FCFCVWeightedWACC calculates a WACC using the coefficient of variance of FCF in place of beta for a measure of risk.
 //
+// In relative mode, the CV is divided by the median CV of a reference universe (see RelativeCV), so that it measures risk relative
+// to the average company, as a beta does. Otherwise the raw CV is used.
+//
 // Arguments:
 //
 //	fcfHistory: The company's Free Cash Flow History as an array of type int.
+//	peerCVs: The FCF coefficients of variance of a reference universe, e.g. a country's companies or a list of peers. Empty to use the raw CV.
 //	debtToEquityRatio: The company's debt-to-equity ratio. This is a measure of the company's financial leverage.
 //	taxRate: The company's effective tax rate. This is the percentage of income that the company pays in taxes.
 //	equityRiskPremium: The equity risk premium. This is the additional return that investors demand for equity investments over and above the risk-free rate.
 //	riskFreeRate: The risk-free rate. This is the return that investors can expect to earn on a risk-free investment, such as a government bond.
+//	costOfDebt: The company's pre-tax cost of debt. See CostOfDebt and SyntheticRating.
 //
 // Returns:
 //
-//	The company's WACC as a float64.
+//	The company's WACC and its components.
+//	An error, if any.
 //
 // Note: The use of the coefficient of variance of FCF as a measure of risk in the WACC calculation is a relatively new approach. It is not yet widely accepted.
 // TODO: insert study of FCF CV here
 func FCFCVWeightedWACC(
 	fcfHistory []int,
+	peerCVs []float64,
 	debtToEquityRatio float64,
 	taxRate float64,
 	equityRiskPremium float64,
 	riskFreeRate float64,
-) float64 {
-	fvfCoefficientOfVariance := CV(fcfHistory)
+	costOfDebt float64,
+) (CostOfCapital, error) {
+	risk := CV(fcfHistory)
 
-	costOfEquity := riskFreeRate + (fvfCoefficientOfVariance * equityRiskPremium)
-	costOfDebt := riskFreeRate * (1 - taxRate)
+	if len(peerCVs) > 0 {
+		var err error
+		risk, err = RelativeCV(risk, peerCVs)
+		if err != nil {
+			return CostOfCapital{}, err
+		}
+	}
 
-	equityWeight := 1 / (1 + debtToEquityRatio)
-	debtWeight := 1 - equityWeight
+	return weightedCostOfCapital(
+		risk,
+		debtToEquityRatio,
+		taxRate,
+		equityRiskPremium,
+		riskFreeRate,
+		costOfDebt,
+	), nil
+}
 
-	wacc := (equityWeight * costOfEquity) + (debtWeight * costOfDebt * (1 - taxRate))
+// RelativeCV scales a coefficient of variance by the median of a reference universe, so that it can be used as a beta substitute,
+// i.e. a company as volatile as the median company scores 1.
+//
+// Arguments:
+//
+//	cv: The company's coefficient of variance.
+//	peerCVs: The coefficients of variance of the reference universe. Peers with a negative or undefined CV (a non-positive mean) are excluded.
+//
+// Returns:
+//
+//	The relative coefficient of variance.
+//	An error, if any.
+func RelativeCV(cv float64, peerCVs []float64) (float64, error) {
+	if cv <= 0 || math.IsNaN(cv) || math.IsInf(cv, 0) {
+		return 0, fmt.Errorf("the mean must be positive for a meaningful coefficient of variance, got a CV of %f", cv)
+	}
 
-	return wacc
+	var valid []float64
+	for _, peer := range peerCVs {
+		if peer > 0 && !math.IsInf(peer, 0) {
+			valid = append(valid, peer)
+		}
+	}
+
+	if len(valid) == 0 {
+		return 0, fmt.Errorf("no peers with a positive coefficient of variance - check input: %v", peerCVs)
+	}
+
+	return cv / Median(valid), nil
+}
+
+// weightedCostOfCapital weights the cost of equity, from a measure of risk, and the after-tax cost of debt by the capital structure.
+func weightedCostOfCapital(
+	risk float64,
+	debtToEquityRatio float64,
+	taxRate float64,
+	equityRiskPremium float64,
+	riskFreeRate float64,
+	costOfDebt float64,
+) CostOfCapital {
+	coc := CostOfCapital{
+		Beta:               risk,
+		CostOfEquity:       CostOfEquity(risk, equityRiskPremium, riskFreeRate),
+		CostOfDebt:         costOfDebt,
+		AfterTaxCostOfDebt: costOfDebt * (1 - taxRate),
+		EquityWeight:       1 / (1 + debtToEquityRatio),
+	}
+	coc.DebtWeight = 1 - coc.EquityWeight
+
+	// the tax shield is applied once, in the after-tax cost of debt
+	coc.WACC = (coc.EquityWeight * coc.CostOfEquity) + (coc.DebtWeight * coc.AfterTaxCostOfDebt)
+
+	return coc
 }
 
 // DCFGrowthExit calculates a DCF analysis using the growth-exit model, taking a standard growth rate and an exit multiple.
@@ -86,6 +303,7 @@ func FCFCVWeightedWACC(
 //	numYears: The number of years in the growth period.
 //	sharesOutstanding: The number of shares outstanding.
 //	discountRate: The discount rate to use for the present value calculation.
+//	opts: Optional settings, e.g. WithEquityBridge for unlevered cash flows or WithShareChange for buybacks.
 //
 // Returns:
 //
@@ -99,11 +317,14 @@ func DCFGrowthExit(
 	numYears int,
 	sharesOutstanding int,
 	discountRate float64,
+	opts ...Option,
 ) (float64, []int, error) {
 	if sharesOutstanding <= 0 {
 		return 0, nil, fmt.Errorf("number of shares outstanding must be greater than zero")
 	}
 
+	s := newSettings(opts)
+
 	var fcfProjections []int
 	totalValue := 0.0
 
@@ -111,18 +332,19 @@ func DCFGrowthExit(
 	for i := 0; i < numYears; i++ {
 		projectedFCF := float64(currentFCF) * math.Pow(1+growthRate, float64(i))
 		fcfProjections = append(fcfProjections, int(projectedFCF))
-		presentValue := projectedFCF / math.Pow(1+discountRate, float64(i+1))
+		presentValue := s.presentValue(projectedFCF, discountRate, i+1)
 		totalValue += presentValue
 	}
 
 	// terminal value using the exit multiple on the last year's FCF
 	lastYearFCF := float64(currentFCF) * math.Pow(1+growthRate, float64(numYears))
 	terminalValue := lastYearFCF * exitMultiple
-	pvTerminalValue := terminalValue / math.Pow(1+discountRate, float64(numYears))
+	pvTerminalValue := s.terminalPresentValue(terminalValue, discountRate, numYears)
+	s.terminal(pvTerminalValue, exitMultiple, ImpliedPerpetualGrowthRate(discountRate, exitMultiple))
 
 	totalValue += pvTerminalValue
 
-	intrinsicValue := totalValue / float64(sharesOutstanding)
+	intrinsicValue := s.perShare(totalValue, sharesOutstanding)
 
 	return intrinsicValue, fcfProjections, nil
 }
@@ -137,6 +359,7 @@ func DCFGrowthExit(
 //	numYears: The number of years in the high-growth stage.
 //	sharesOutstanding: The number of shares outstanding.
 //	discountRate: The discount rate to use for the present value calculation.
+//	opts: Optional settings, e.g. WithEquityBridge for unlevered cash flows or WithShareChange for buybacks.
 //
 // Returns:
 //
@@ -150,6 +373,7 @@ func DCFTwoStage(
 	numYears int,
 	sharesOutstanding int,
 	discountRate float64,
+	opts ...Option,
 ) (float64, []int, error) {
 	if sharesOutstanding <= 0 {
 		return 0, nil, fmt.Errorf("number of shares outstanding must be greater than zero")
@@ -158,6 +382,8 @@ func DCFTwoStage(
 		return 0, nil, fmt.Errorf("discount rate must be greater than the perpetual growth rate")
 	}
 
+	s := newSettings(opts)
+
 	var fcfProjections []int
 
 	totalValue := 0.0
@@ -166,19 +392,102 @@ func DCFTwoStage(
 	for i := 1; i <= numYears; i++ {
 		projectedFCF := float64(currentFCF) * math.Pow(1+growthRate, float64(i))
 		fcfProjections = append(fcfProjections, int(projectedFCF))
-		presentValue := projectedFCF / math.Pow(1+discountRate, float64(i))
+		presentValue := s.presentValue(projectedFCF, discountRate, i)
 		totalValue += presentValue
 	}
 
 	// stable growth phase
 	lastYearFCF := float64(currentFCF) * math.Pow(1+growthRate, float64(numYears))
 	terminalValue := (lastYearFCF * (1 + perpetualGrowthRate)) / (discountRate - perpetualGrowthRate)
-	pvTerminalValue := terminalValue / math.Pow(1+discountRate, float64(numYears))
+	pvTerminalValue := s.terminalPresentValue(terminalValue, discountRate, numYears)
+	s.terminal(
+		pvTerminalValue,
+		ImpliedExitMultiple(discountRate, perpetualGrowthRate),
+		perpetualGrowthRate,
+	)
 
 	totalValue += pvTerminalValue
 
 	// per share value
-	intrinsicValue := totalValue / float64(sharesOutstanding)
+	intrinsicValue := s.perShare(totalValue, sharesOutstanding)
+
+	return intrinsicValue, fcfProjections, nil
+}
+
+// DCFThreeStage calculates a DCF analysis using a three-stage model, with a high-growth stage, a transition stage where growth fades linearly to the perpetual growth rate, and a terminal, perpetual-growth stage.
+//
+// Arguments:
+//
+//	currentFCF: The current free cash flow of the company.
+//	growthRate: The annual growth rate of the free cash flow during the high-growth stage.
+//	perpetualGrowthRate: The perpetual growth rate of the free cash flow after the transition stage.
+//	highGrowthYears: The number of years in the high-growth stage.
+//	transitionYears: The number of years in the transition stage.
+//	sharesOutstanding: The number of shares outstanding.
+//	discountRate: The discount rate to use for the present value calculation.
+//	opts: Optional settings, e.g. WithEquityBridge for unlevered cash flows or WithShareChange for buybacks.
+//
+// Returns:
+//
+//	The intrinsic value of the company
+//	The projected FCFs
+//	An error, if any
+func DCFThreeStage(
+	currentFCF int,
+	growthRate float64,
+	perpetualGrowthRate float64,
+	highGrowthYears int,
+	transitionYears int,
+	sharesOutstanding int,
+	discountRate float64,
+	opts ...Option,
+) (float64, []int, error) {
+	if sharesOutstanding <= 0 {
+		return 0, nil, fmt.Errorf("number of shares outstanding must be greater than zero")
+	}
+	if discountRate <= perpetualGrowthRate {
+		return 0, nil, fmt.Errorf("discount rate must be greater than the perpetual growth rate")
+	}
+	if transitionYears < 0 {
+		return 0, nil, fmt.Errorf("number of transition years must not be negative")
+	}
+
+	s := newSettings(opts)
+
+	var fcfProjections []int
+
+	totalValue := 0.0
+	projectedFCF := float64(currentFCF)
+
+	// high growth phase
+	for i := 1; i <= highGrowthYears; i++ {
+		projectedFCF *= 1 + growthRate
+		fcfProjections = append(fcfProjections, int(projectedFCF))
+		totalValue += s.presentValue(projectedFCF, discountRate, i)
+	}
+
+	// transition phase, growth fades linearly until it reaches the perpetual rate in the final year
+	for i := 1; i <= transitionYears; i++ {
+		fadedGrowthRate := growthRate - (growthRate-perpetualGrowthRate)*float64(i)/float64(transitionYears)
+		projectedFCF *= 1 + fadedGrowthRate
+		fcfProjections = append(fcfProjections, int(projectedFCF))
+		totalValue += s.presentValue(projectedFCF, discountRate, highGrowthYears+i)
+	}
+
+	// stable growth phase
+	numYears := highGrowthYears + transitionYears
+	terminalValue := (projectedFCF * (1 + perpetualGrowthRate)) / (discountRate - perpetualGrowthRate)
+	pvTerminalValue := s.terminalPresentValue(terminalValue, discountRate, numYears)
+	s.terminal(
+		pvTerminalValue,
+		ImpliedExitMultiple(discountRate, perpetualGrowthRate),
+		perpetualGrowthRate,
+	)
+
+	totalValue += pvTerminalValue
+
+	// per share value
+	intrinsicValue := s.perShare(totalValue, sharesOutstanding)
 
 	return intrinsicValue, fcfProjections, nil
 }
@@ -193,11 +502,12 @@ func DCFTwoStage(
 //	numYears: The number of years in the high-growth stage.
 //	sharesOutstanding: The number of shares outstanding.
 //	discountRate: The discount rate to use for the present value calculation.
+//	opts: Optional settings, e.g. WithMidYear, WithStub or WithDiscountCurve.
 //
 // Returns:
 //
 //	The intrinsic value of the company.
-//	The projected FCFs.
+//	The projected dividends.
 //	An error or nil.
 func DDMTwoStage(
 	currentDividend int,
@@ -206,6 +516,7 @@ func DDMTwoStage(
 	numYears int,
 	sharesOutstanding int,
 	discountRate float64,
+	opts ...Option,
 ) (float64, []int, error) {
 	if sharesOutstanding <= 0 {
 		return 0, nil, fmt.Errorf("number of shares outstanding must be greater than zero")
@@ -214,27 +525,298 @@ func DDMTwoStage(
 		return 0, nil, fmt.Errorf("discount rate must be greater than the perpetual growth rate")
 	}
 
+	s := newSettings(opts)
+
 	totalValue := 0.0
 	var projectedDividends []int
 
 	// Calculate present value of dividends for the high-growth stage
 	dividends := float64(currentDividend)
 	for i := 1; i <= numYears; i++ {
-		presentValue := dividends / math.Pow(1+discountRate, float64(i))
-		totalValue += presentValue
 		dividends *= (1 + growthRate)
 		projectedDividends = append(projectedDividends, int(dividends))
+		totalValue += s.presentValue(dividends, discountRate, i)
 	}
 
-	// Calculate terminal value using the Gordon Growth Model
-	terminalValue := dividends / (discountRate - perpetualGrowthRate)
+	// Calculate terminal value using the Gordon Growth Model, discounted from the final projected year
+	terminalValue := dividends * (1 + perpetualGrowthRate) / (discountRate - perpetualGrowthRate)
+	pvTerminalValue := s.terminalPresentValue(terminalValue, discountRate, numYears)
+	s.terminal(
+		pvTerminalValue,
+		ImpliedExitMultiple(discountRate, perpetualGrowthRate),
+		perpetualGrowthRate,
+	)
+
+	totalValue += pvTerminalValue
 
 	// Calculate intrinsic value per share
-	intrinsicValue := (totalValue + terminalValue) / float64(sharesOutstanding)
+	intrinsicValue := s.perShare(totalValue, sharesOutstanding)
 
 	return intrinsicValue, projectedDividends, nil
 }
 
+// DDMHModel calculates a DDM using the Fuller-Hsia H-model, where dividend growth declines linearly from a short-term rate to a long-term rate.
+//
+// Arguments:
+//
+//	currentDividend: The current dividend of the company.
+//	shortTermGrowthRate: The initial annual growth rate of the dividend.
+//	longTermGrowthRate: The long-term (perpetual) growth rate of the dividend.
+//	halfLife: The half-life of the high-growth period in years, i.e. half of the years it takes growth to decline to the long-term rate.
+//	sharesOutstanding: The number of shares outstanding.
+//	discountRate: The discount rate to use for the present value calculation.
+//	opts: Optional settings, e.g. WithMidYear or WithStub. As the H-model is a closed form, these roll the value forward from the fiscal year end, and WithDiscountCurve is not supported.
+//
+// Returns:
+//
+//	The intrinsic value of the company.
+//	The projected dividends over the period of declining growth.
+//	An error or nil.
+func DDMHModel(
+	currentDividend int,
+	shortTermGrowthRate float64,
+	longTermGrowthRate float64,
+	halfLife float64,
+	sharesOutstanding int,
+	discountRate float64,
+	opts ...Option,
+) (float64, []int, error) {
+	if sharesOutstanding <= 0 {
+		return 0, nil, fmt.Errorf("number of shares outstanding must be greater than zero")
+	}
+	if discountRate <= longTermGrowthRate {
+		return 0, nil, fmt.Errorf("discount rate must be greater than the long-term growth rate")
+	}
+	if halfLife < 0 {
+		return 0, nil, fmt.Errorf("half-life must not be negative")
+	}
+
+	s := newSettings(opts)
+	if len(s.curve) > 0 {
+		return 0, nil, fmt.Errorf(
+			"the H-model is a closed form and requires a single discount rate, not a discount curve",
+		)
+	}
+
+	dividend := float64(currentDividend)
+
+	// the H-model closed form, the Gordon growth value plus the premium from above-normal growth
+	totalValue := dividend * ((1 + longTermGrowthRate) + halfLife*(shortTermGrowthRate-longTermGrowthRate)) /
+		(discountRate - longTermGrowthRate)
+
+	// the closed form values the company at the fiscal year end, roll it forward to the valuation date
+	totalValue *= (1 + discountRate) / s.discountFactor(discountRate, 1)
+
+	// projected dividends while growth declines linearly to the long-term rate over 2H years
+	var projectedDividends []int
+	declineYears := int(math.Round(2 * halfLife))
+	for i := 1; i <= declineYears; i++ {
+		growthRate := shortTermGrowthRate - (shortTermGrowthRate-longTermGrowthRate)*float64(i-1)/float64(declineYears)
+		dividend *= 1 + growthRate
+		projectedDividends = append(projectedDividends, int(dividend))
+	}
+
+	intrinsicValue := totalValue / float64(sharesOutstanding)
+
+	return intrinsicValue, projectedDividends, nil
+}
+
+// ResidualIncome calculates a residual income (excess return) valuation, i.e. the current book value plus the present value of the returns earned above the cost of equity.
+//
+// This is suited to financials (banks, insurers), where free cash flow is not a meaningful measure.
+//
+// Arguments:
+//
+//	currentBookValue: The current book value of equity of the company.
+//	roe: The return on equity expected over the projection period.
+//	bookGrowthRate: The annual growth rate of the book value of equity.
+//	perpetualGrowthRate: The perpetual growth rate of the residual income after the projection period.
+//	numYears: The number of years in the projection period.
+//	sharesOutstanding: The number of shares outstanding.
+//	costOfEquity: The cost of equity to use for the present value calculation.
+//
+// Returns:
+//
+//	The intrinsic value of the company.
+//	The projected residual incomes.
+//	An error, if any.
+func ResidualIncome(
+	currentBookValue int,
+	roe float64,
+	bookGrowthRate float64,
+	perpetualGrowthRate float64,
+	numYears int,
+	sharesOutstanding int,
+	costOfEquity float64,
+) (float64, []int, error) {
+	if sharesOutstanding <= 0 {
+		return 0, nil, fmt.Errorf("number of shares outstanding must be greater than zero")
+	}
+	if currentBookValue <= 0 {
+		return 0, nil, fmt.Errorf("book value must be greater than zero")
+	}
+	if costOfEquity <= perpetualGrowthRate {
+		return 0, nil, fmt.Errorf("cost of equity must be greater than the perpetual growth rate")
+	}
+
+	var residualIncomes []int
+
+	bookValue := float64(currentBookValue)
+	totalValue := bookValue
+
+	// residual income earned on the opening book value of each year
+	for i := 1; i <= numYears; i++ {
+		residualIncome := (roe - costOfEquity) * bookValue
+		residualIncomes = append(residualIncomes, int(residualIncome))
+		totalValue += residualIncome / math.Pow(1+costOfEquity, float64(i))
+		bookValue *= 1 + bookGrowthRate
+	}
+
+	// terminal value of the residual income that continues to grow after the projection period
+	terminalResidualIncome := (roe - costOfEquity) * bookValue
+	terminalValue := terminalResidualIncome / (costOfEquity - perpetualGrowthRate)
+	pvTerminalValue := terminalValue / math.Pow(1+costOfEquity, float64(numYears))
+
+	totalValue += pvTerminalValue
+
+	intrinsicValue := totalValue / float64(sharesOutstanding)
+
+	return intrinsicValue, residualIncomes, nil
+}
+
+// EarningsPowerValue calculates Greenwald's Earnings Power Value (EPV), the value of the company's current, sustainable earnings assuming no growth.
+//
+// Arguments:
+//
+//	revenue: The current revenue of the company.
+//	ebitMargin: The normalized EBIT (operating) margin, e.g. an average over the business cycle.
+//	taxRate: The company's effective tax rate.
+//	depreciation: The depreciation and amortization charge of the company.
+//	maintenanceCapex: The capital expenditure required to maintain (not grow) the business.
+//	sharesOutstanding: The number of shares outstanding.
+//	discountRate: The discount rate used to capitalize the earnings.
+//	opts: Optional settings, e.g. WithEquityBridge, as the capitalized NOPAT is an enterprise value.
+//
+// Returns:
+//
+//	The EPV per share.
+//	The adjusted (after-tax, maintenance capex adjusted) earnings being capitalized.
+//	An error, if any.
+func EarningsPowerValue(
+	revenue int,
+	ebitMargin float64,
+	taxRate float64,
+	depreciation int,
+	maintenanceCapex int,
+	sharesOutstanding int,
+	discountRate float64,
+	opts ...Option,
+) (float64, float64, error) {
+	if sharesOutstanding <= 0 {
+		return 0, 0, fmt.Errorf("number of shares outstanding must be greater than zero")
+	}
+	if discountRate <= 0 {
+		return 0, 0, fmt.Errorf("discount rate must be greater than zero")
+	}
+
+	s := newSettings(opts)
+
+	normalizedEBIT := float64(revenue) * ebitMargin
+	nopat := normalizedEBIT * (1 - taxRate)
+
+	// replace the depreciation charge with the capex needed to sustain current earnings
+	adjustedEarnings := nopat + float64(depreciation) - float64(maintenanceCapex)
+
+	epv := adjustedEarnings / discountRate
+
+	return s.perShare(epv, sharesOutstanding), adjustedEarnings, nil
+}
+
+// Margins calculates the margin of each value over the matching revenue, e.g. the EBIT margin for each year.
+//
+// Arguments:
+//
+//	values: An array of type int, e.g. EBIT history.
+//	revenue: An array of type int with the revenue for the same periods.
+//
+// Returns:
+//
+//	The margins, as an array of float64.
+//	An error, if any.
+func Margins(values []int, revenue []int) ([]float64, error) {
+	if len(values) != len(revenue) {
+		return nil, fmt.Errorf(
+			"values and revenue must cover the same periods - check input: %v, %v",
+			values,
+			revenue,
+		)
+	}
+
+	margins := make([]float64, 0, len(values))
+	for i := range values {
+		if revenue[i] == 0 {
+			return nil, fmt.Errorf(
+				"revenue is zero, margin calculation is not possible - check input: %v",
+				revenue,
+			)
+		}
+		margins = append(margins, float64(values[i])/float64(revenue[i]))
+	}
+
+	return margins, nil
+}
+
+// UnleveredFCF converts a levered FCF history into an unlevered FCF (FCFF) history, by adding back after-tax interest expense.
+//
+// Arguments:
+//
+//	fcfHistory: The company's Free Cash Flow History as an array of type int.
+//	interestExpense: The company's interest expense for the same periods, as an array of type int.
+//	taxRate: The company's effective tax rate.
+//
+// Returns:
+//
+//	The unlevered FCF history.
+//	An error, if any.
+func UnleveredFCF(fcfHistory []int, interestExpense []int, taxRate float64) ([]int, error) {
+	if len(fcfHistory) != len(interestExpense) {
+		return nil, fmt.Errorf(
+			"FCF and interest expense must cover the same periods - check input: %v, %v",
+			fcfHistory,
+			interestExpense,
+		)
+	}
+
+	unlevered := make([]int, 0, len(fcfHistory))
+	for i, fcf := range fcfHistory {
+		unlevered = append(unlevered, fcf+int(float64(interestExpense[i])*(1-taxRate)))
+	}
+
+	return unlevered, nil
+}
+
+// Mean calculates the arithmetic mean of an array of type int or float64.
+//
+// Arguments:
+//
+//	values: An array of type int or float64.
+//
+// Returns:
+//
+//	The mean of the array.
+func Mean[T int | float64](values []T) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	var sum float64
+	for _, v := range values {
+		sum += float64(v)
+	}
+
+	return sum / float64(len(values))
+}
+
 // CV calculates the coefficient of variance of an array of type int.
 //
 // Arguments:
@@ -375,3 +957,224 @@ func Upside(targetPrice float64, currentPrice float64) (float64, error) {
 
 	return upside, nil
 }
+
+// ProbabilityWeighted calculates the expected value of a set of scenarios, e.g. the fair values of bear, base and bull cases.
+//
+// Arguments:
+//
+//	values: The value of each scenario.
+//	probabilities: The probability of each scenario, which must sum to 1.
+//
+// Returns:
+//
+//	The probability-weighted value.
+//	An error, if any.
+func ProbabilityWeighted(values []float64, probabilities []float64) (float64, error) {
+	if len(values) == 0 || len(values) != len(probabilities) {
+		return 0, fmt.Errorf(
+			"each value requires a probability - check input: %v, %v",
+			values,
+			probabilities,
+		)
+	}
+
+	var weighted, total float64
+	for i, probability := range probabilities {
+		if probability < 0 {
+			return 0, fmt.Errorf("probabilities must not be negative - check input: %v", probabilities)
+		}
+		weighted += values[i] * probability
+		total += probability
+	}
+
+	if math.Abs(total-1) > 1e-6 {
+		return 0, fmt.Errorf("probabilities must sum to 1, got %.4f", total)
+	}
+
+	return weighted, nil
+}
+
+// SensitivityMatrix calculates intrinsic values for every combination of two model inputs.
+//
+// Arguments:
+//
+//	rows: The values of the first input, one per row.
+//	columns: The values of the second input, one per column.
+//	valueAt: The valuation model, returning the intrinsic value per share for a row and column input.
+//
+// Returns:
+//
+//	A matrix of intrinsic values indexed by row then column. Combinations the model rejects (e.g. a discount rate below the perpetual growth rate) are NaN.
+func SensitivityMatrix(
+	rows []float64,
+	columns []float64,
+	valueAt func(row float64, column float64) (float64, error),
+) [][]float64 {
+	matrix := make([][]float64, len(rows))
+
+	for i, row := range rows {
+		matrix[i] = make([]float64, len(columns))
+
+		for j, column := range columns {
+			value, err := valueAt(row, column)
+			if err != nil {
+				value = math.NaN()
+			}
+			matrix[i][j] = value
+		}
+	}
+
+	return matrix
+}
+
+// Steps returns a range of values centered on a value, e.g. Steps(0.10, 0.01, 2) returns [0.08, 0.09, 0.10, 0.11, 0.12].
+//
+// Arguments:
+//
+//	center: The value in the middle of the range.
+//	step: The distance between each value.
+//	count: The number of values either side of the center.
+//
+// Returns:
+//
+//	The range of values, in ascending order.
+func Steps(center float64, step float64, count int) []float64 {
+	values := make([]float64, 0, 2*count+1)
+
+	for i := -count; i <= count; i++ {
+		values = append(values, center+float64(i)*step)
+	}
+
+	return values
+}
+
+// ImpliedExitMultiple calculates the multiple of the final year's cash flow that a perpetual growth (Gordon) terminal value represents.
+//
+// Arguments:
+//
+//	discountRate: The discount rate.
+//	perpetualGrowthRate: The perpetual growth rate of the cash flow.
+//
+// Returns:
+//
+//	The implied exit multiple.
+func ImpliedExitMultiple(discountRate float64, perpetualGrowthRate float64) float64 {
+	return (1 + perpetualGrowthRate) / (discountRate - perpetualGrowthRate)
+}
+
+// ImpliedPerpetualGrowthRate calculates the perpetual growth rate at which a perpetual growth (Gordon) terminal value equals an exit multiple.
+//
+// Arguments:
+//
+//	discountRate: The discount rate.
+//	exitMultiple: The exit multiple of the final year's cash flow.
+//
+// Returns:
+//
+//	The implied perpetual growth rate.
+func ImpliedPerpetualGrowthRate(discountRate float64, exitMultiple float64) float64 {
+	return (exitMultiple*discountRate - 1) / (exitMultiple + 1)
+}
+
+// ImpliedGrowthRate solves for the growth rate at which a valuation model equals the current price (i.e. a reverse DCF).
+//
+// Arguments:
+//
+//	price: The current price per share.
+//	valueAt: The valuation model, returning the intrinsic value per share for a given growth rate.
+//
+// Returns:
+//
+//	The growth rate implied by the current price.
+//	An error, if any.
+func ImpliedGrowthRate(
+	price float64,
+	valueAt func(growthRate float64) (float64, error),
+) (float64, error) {
+	if price <= 0 {
+		return 0.0, fmt.Errorf("price must be greater than zero")
+	}
+
+	impliedRate, err := bisect(func(growthRate float64) (float64, error) {
+		value, err := valueAt(growthRate)
+		return value - price, err
+	}, minImpliedGrowthRate, maxImpliedGrowthRate)
+	if err != nil {
+		return 0.0, fmt.Errorf("error solving for the implied growth rate: %w", err)
+	}
+
+	return impliedRate, nil
+}
+
+// ImpliedDiscountRate solves for the discount rate at which a valuation model equals the current price, i.e. the IRR of buying at the current price.
+//
+// Arguments:
+//
+//	price: The current price per share.
+//	minRate: The exclusive lower bound of the search, e.g. the perpetual growth rate for a model with a Gordon growth terminal value.
+//	valueAt: The valuation model, returning the intrinsic value per share for a given discount rate.
+//
+// Returns:
+//
+//	The discount rate implied by the current price.
+//	An error, if any.
+func ImpliedDiscountRate(
+	price float64,
+	minRate float64,
+	valueAt func(discountRate float64) (float64, error),
+) (float64, error) {
+	if price <= 0 {
+		return 0.0, fmt.Errorf("price must be greater than zero")
+	}
+
+	if minRate >= maxImpliedDiscountRate {
+		return 0.0, fmt.Errorf(
+			"minimum discount rate must be less than %.2f",
+			maxImpliedDiscountRate,
+		)
+	}
+
+	impliedRate, err := bisect(func(discountRate float64) (float64, error) {
+		value, err := valueAt(discountRate)
+		return value - price, err
+	}, minRate+solverTolerance, maxImpliedDiscountRate)
+	if err != nil {
+		return 0.0, fmt.Errorf("error solving for the implied discount rate: %w", err)
+	}
+
+	return impliedRate, nil
+}
+
+// bisect finds a root of f between lo and hi using the bisection method. f(lo) and f(hi) must have opposite signs.
+func bisect(f func(x float64) (float64, error), lo float64, hi float64) (float64, error) {
+	fLo, err := f(lo)
+	if err != nil {
+		return 0, err
+	}
+
+	fHi, err := f(hi)
+	if err != nil {
+		return 0, err
+	}
+
+	if fLo*fHi > 0 {
+		return 0, fmt.Errorf("no solution between %.4f and %.4f", lo, hi)
+	}
+
+	for i := 0; i < solverMaxIterations && hi-lo > solverTolerance; i++ {
+		mid := (lo + hi) / 2
+
+		fMid, err := f(mid)
+		if err != nil {
+			return 0, err
+		}
+
+		if fLo*fMid <= 0 {
+			hi = mid
+		} else {
+			lo, fLo = mid, fMid
+		}
+	}
+
+	return (lo + hi) / 2, nil
+}
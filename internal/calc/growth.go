@@ -0,0 +1,116 @@
+package calc
+
+import "fmt"
+
+// MedianGrowth calculates the median year-on-year growth of an array of ints (we assume it to be annual).
+// Unlike the CAGR, it is not skewed by an unusual first or last year.
+//
+// Arguments:
+//
+//	values: An array of type int, e.g. FCF history.
+//
+// Returns:
+//
+//	The median year-on-year growth rate.
+//	An error, if any.
+func MedianGrowth(values []int) (float64, error) {
+	changes := YoYChanges(values)
+	if len(changes) == 0 {
+		return 0, fmt.Errorf(
+			"at least two consecutive non-zero values are required - check input: %v",
+			values,
+		)
+	}
+
+	return Median(changes), nil
+}
+
+// ReinvestmentRate calculates the share of after-tax operating income (NOPAT) that is reinvested rather than paid out as FCF,
+// over the whole history.
+//
+// Arguments:
+//
+//	fcfHistory: An array of type int with the FCF history.
+//	ebitHistory: An array of type int with the EBIT for the same periods.
+//	taxRate: The tax rate.
+//
+// Returns:
+//
+//	The reinvestment rate.
+//	An error, if any.
+func ReinvestmentRate(fcfHistory []int, ebitHistory []int, taxRate float64) (float64, error) {
+	if len(fcfHistory) != len(ebitHistory) {
+		return 0, fmt.Errorf(
+			"FCF and EBIT must cover the same periods - check input: %v, %v",
+			fcfHistory,
+			ebitHistory,
+		)
+	}
+
+	var fcf, nopat float64
+	for i := range fcfHistory {
+		fcf += float64(fcfHistory[i])
+		nopat += float64(ebitHistory[i]) * (1 - taxRate)
+	}
+
+	if nopat <= 0 {
+		return 0, fmt.Errorf(
+			"NOPAT must be positive to calculate a reinvestment rate - check input: %v",
+			ebitHistory,
+		)
+	}
+
+	return 1 - fcf/nopat, nil
+}
+
+// FundamentalGrowth calculates the growth rate a company can sustain from its reinvestment, i.e. reinvestment rate × ROIC.
+//
+// Arguments:
+//
+//	reinvestmentRate: The share of NOPAT reinvested.
+//	roic: The return on invested capital.
+//
+// Returns:
+//
+//	The fundamental growth rate.
+func FundamentalGrowth(reinvestmentRate float64, roic float64) float64 {
+	return reinvestmentRate * roic
+}
+
+// ShareAdjusted restates each value of a series at the latest share count, so that its growth is the growth per share.
+// E.g. a company that doubles its FCF while doubling its shares outstanding shows no growth.
+//
+// Arguments:
+//
+//	values: An array of type int, e.g. FCF history.
+//	sharesHistory: An array of type int with the diluted shares outstanding for the same periods.
+//
+// Returns:
+//
+//	The share-adjusted values, as an array of type int.
+//	An error, if any.
+func ShareAdjusted(values []int, sharesHistory []int) ([]int, error) {
+	if len(values) != len(sharesHistory) {
+		return nil, fmt.Errorf(
+			"values and shares must cover the same periods - check input: %v, %v",
+			values,
+			sharesHistory,
+		)
+	}
+
+	latest := float64(sharesHistory[len(sharesHistory)-1])
+
+	adjusted := make([]int, 0, len(values))
+	for i := range values {
+		if sharesHistory[i] <= 0 {
+			return nil, fmt.Errorf(
+				"number of shares outstanding must be greater than zero - check input: %v",
+				sharesHistory,
+			)
+		}
+
+		adjusted = append(adjusted, int(float64(values[i])*latest/float64(sharesHistory[i])))
+	}
+
+	return adjusted, nil
+}
@@ -0,0 +1,55 @@
+package calc
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func Test_MedianGrowth(t *testing.T) {
+	growth, err := MedianGrowth(fcfHistory)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if math.Abs(growth-0.24740832875309737) > 1e-9 {
+		fmt.Println(growth)
+		t.Fatalf(`MedianGrowth(%v) = %f`, fcfHistory, growth)
+	}
+}
+
+func Test_ReinvestmentRate(t *testing.T) {
+	fcf := []int{40, 60}
+	ebit := []int{100, 100}
+	rate, err := ReinvestmentRate(fcf, ebit, 0.2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 100 of FCF from 160 of NOPAT
+	if math.Abs(rate-0.375) > 1e-9 {
+		fmt.Println(rate)
+		t.Fatalf(`ReinvestmentRate(%v, %v, %f) = %f`, fcf, ebit, 0.2, rate)
+	}
+
+	growth := FundamentalGrowth(rate, 0.2)
+	if math.Abs(growth-0.075) > 1e-9 {
+		t.Fatalf(`FundamentalGrowth(%f, %f) = %f`, rate, 0.2, growth)
+	}
+}
+
+func Test_ShareAdjusted(t *testing.T) {
+	values := []int{100, 200}
+	sharesHistory := []int{50, 100}
+	adjusted, err := ShareAdjusted(values, sharesHistory)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// FCF doubled with the share count, so FCF per share is flat
+	if !reflect.DeepEqual(adjusted, []int{200, 200}) {
+		fmt.Println(adjusted)
+		t.Fatalf(`ShareAdjusted(%v, %v) = %v`, values, sharesHistory, adjusted)
+	}
+}
@@ -0,0 +1,125 @@
+package calc
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Median calculates the median of an array of type int or float64.
+//
+// Arguments:
+//
+//	values: An array of type int or float64.
+//
+// Returns:
+//
+//	The median of the array.
+func Median[T int | float64](values []T) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	sorted := make([]float64, 0, len(values))
+	for _, v := range values {
+		sorted = append(sorted, float64(v))
+	}
+	sort.Float64s(sorted)
+
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+
+	return sorted[mid]
+}
+
+// TrimmedMean calculates the mean of an array of type int, after discarding a share of the lowest and highest values.
+//
+// Arguments:
+//
+//	values: An array of type int.
+//	trim: The share of values to discard from each end, e.g. 0.2 discards the lowest and highest of five values.
+//
+// Returns:
+//
+//	The trimmed mean of the array.
+func TrimmedMean(values []int, trim float64) float64 {
+	sorted := append([]int(nil), values...)
+	sort.Ints(sorted)
+
+	cut := int(float64(len(sorted)) * math.Max(0, math.Min(0.5, trim)))
+	if len(sorted)-2*cut <= 0 {
+		return Median(sorted)
+	}
+
+	return Mean(sorted[cut : len(sorted)-cut])
+}
+
+// LogLinearTrend fits an exponential trend through an array of ints (we assume it to be annual), and returns the trend value in the latest year.
+// Unlike the latest value, the trend value is not skewed by a single unusually good or bad year.
+//
+// Arguments:
+//
+//	values: An array of type int, e.g. FCF history.
+//
+// Returns:
+//
+//	The trend value in the latest year.
+//	The annual growth rate of the trend.
+//	An error, if any.
+func LogLinearTrend(values []int) (float64, float64, error) {
+	if len(values) < 2 {
+		return 0, 0, fmt.Errorf(
+			"at least two values are required to fit a trend - check input: %v",
+			values,
+		)
+	}
+
+	var sumX, sumY, sumXY, sumXX float64
+	for i, v := range values {
+		if v <= 0 {
+			return 0, 0, fmt.Errorf(
+				"values must be positive to fit a log-linear trend - check input: %v",
+				values,
+			)
+		}
+
+		x := float64(i)
+		y := math.Log(float64(v))
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+
+	n := float64(len(values))
+	slope := (n*sumXY - sumX*sumY) / (n*sumXX - sumX*sumX)
+	intercept := (sumY - slope*sumX) / n
+
+	return math.Exp(intercept + slope*(n-1)), math.Exp(slope) - 1, nil
+}
+
+// MarginNormalized applies the average margin of a series over revenue to the latest revenue, e.g. the average FCF margin × the latest revenue.
+//
+// Arguments:
+//
+//	values: An array of type int, e.g. FCF history.
+//	revenue: An array of type int with the revenue for the same periods.
+//
+// Returns:
+//
+//	The margin-normalized value in the latest year.
+//	An error, if any.
+func MarginNormalized(values []int, revenue []int) (float64, error) {
+	margins, err := Margins(values, revenue)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(margins) == 0 {
+		return 0, fmt.Errorf("no history to normalize - check input: %v", values)
+	}
+
+	return Mean(margins) * float64(revenue[len(revenue)-1]), nil
+}
@@ -0,0 +1,59 @@
+package calc
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func Test_Median(t *testing.T) {
+	odd := Median([]int{5, 1, 3})
+	even := Median([]float64{4, 1, 3, 2})
+
+	if odd != 3 || even != 2.5 {
+		t.Fatalf(`Median odd = %f, even = %f`, odd, even)
+	}
+}
+
+func Test_TrimmedMean(t *testing.T) {
+	values := []int{-500, 100, 110, 120, 1000}
+	mean := TrimmedMean(values, 0.2)
+
+	// the outliers at each end are discarded
+	if mean != 110 {
+		fmt.Println(mean)
+		t.Fatalf(`TrimmedMean(%v, %f) = %f`, values, 0.2, mean)
+	}
+}
+
+func Test_LogLinearTrend(t *testing.T) {
+	values := []int{100, 110, 121, 133, 146}
+	trend, growth, err := LogLinearTrend(values)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if math.Abs(trend-146) > 0.5 || math.Abs(growth-0.10) > 0.001 {
+		fmt.Println(trend, growth)
+		t.Fatalf(`LogLinearTrend(%v) = %f, %f`, values, trend, growth)
+	}
+
+	if _, _, err := LogLinearTrend([]int{100, -10, 120}); err == nil {
+		t.Fatalf(`LogLinearTrend should reject non-positive values`)
+	}
+}
+
+func Test_MarginNormalized(t *testing.T) {
+	values := []int{10, 30}
+	revenue := []int{100, 200}
+	normalized, err := MarginNormalized(values, revenue)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// average margin of 12.5% on the latest revenue
+	if math.Abs(normalized-25) > 1e-9 {
+		fmt.Println(normalized)
+		t.Fatalf(`MarginNormalized(%v, %v) = %f`, values, revenue, normalized)
+	}
+}
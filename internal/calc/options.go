@@ -0,0 +1,264 @@
+package calc
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Option configures optional behaviour of the valuation models, e.g. calc.DCFTwoStage(..., calc.WithEquityBridge(bridge)).
+type Option func(s *settings)
+
+type settings struct {
+	bridge      *EquityBridge
+	breakdown   *Breakdown
+	shareChange float64
+	dilution    float64
+	midYear     bool
+	stub        float64
+	curve       []float64
+}
+
+// EquityBridge holds the claims senior to common equity, which are deducted from an enterprise value to arrive at equity value.
+type EquityBridge struct {
+	NetDebt          int
+	MinorityInterest int
+	PreferredEquity  int
+}
+
+// Breakdown reports the intermediate values of a valuation model.
+type Breakdown struct {
+	// EnterpriseValue is the total present value of the projected cash flows and terminal value.
+	EnterpriseValue float64
+	// EquityValue is the value attributable to the current common shareholders, after any equity bridge and dilution.
+	EquityValue float64
+	// Dilution is the present value attributable to shares issued during the projection, see WithShareChange.
+	Dilution float64
+	// DiscountRates is the discount rate applied in each projected year.
+	DiscountRates []float64
+	// DiscountFactors is the present value of 1 received with each projected year's cash flow.
+	DiscountFactors []float64
+	// TerminalValue is the present value of the terminal value, included in the enterprise value.
+	TerminalValue float64
+	// TerminalShare is the share of the enterprise value that comes from the terminal value.
+	// It is NaN when the enterprise value isn't positive.
+	TerminalShare float64
+	// TerminalMultiple is the multiple of the final year's cash flow paid for the terminal value.
+	// It is the input of the growth-exit model, and implied by the perpetual growth rate in the perpetual growth models.
+	TerminalMultiple float64
+	// TerminalGrowthRate is the perpetual growth rate of the terminal value.
+	// It is the input of the perpetual growth models, and implied by the exit multiple in the growth-exit model.
+	TerminalGrowthRate float64
+}
+
+// WithEquityBridge treats the cash flows as unlevered (FCFF), so that the model's total present value is an enterprise value,
+// and deducts net debt, minority interest and preferred equity before dividing by the shares outstanding.
+func WithEquityBridge(bridge EquityBridge) Option {
+	return func(s *settings) {
+		s.bridge = &bridge
+	}
+}
+
+// WithBreakdown populates b with the intermediate values of the model.
+func WithBreakdown(b *Breakdown) Option {
+	return func(s *settings) {
+		s.breakdown = b
+	}
+}
+
+// WithShareChange projects the share count to grow at an annual rate, e.g. 0.02 for net issuance of 2% a year,
+// so that each year's cash flow, and the terminal value, is attributed to the shares outstanding in that year.
+// Only dilution is applied: buybacks are paid for from the cash flows, so shrinking the share count as well would count them twice.
+func WithShareChange(rate float64) Option {
+	return func(s *settings) {
+		s.shareChange = rate
+	}
+}
+
+// WithMidYear discounts each year's cash flow from the middle of the year rather than the end,
+// as cash flows are received throughout the year. The terminal value is still discounted from the end of the final year.
+func WithMidYear() Option {
+	return func(s *settings) {
+		s.midYear = true
+	}
+}
+
+// WithStub values the company partway through its fiscal year, where the first projected year is a partial (stub) period.
+// The stub is the share of the first year remaining, between 0 and 1; see StubPeriod.
+// Only the remaining share of the first year's cash flow is counted, and each later year is discounted from the end of the stub.
+func WithStub(stub float64) Option {
+	return func(s *settings) {
+		s.stub = stub
+	}
+}
+
+// WithDiscountCurve adds a spread to the discount rate in each projected year, in place of a single rate for every year,
+// e.g. []float64{0.04, 0.03, 0.02, 0.01} for a risk premium that fades as a startup matures, or []float64{-0.01, -0.005} for a rising risk-free rate.
+// Years beyond the curve are discounted at the discount rate itself, which remains the long-run rate of the terminal value.
+// As the curve is relative to the discount rate, models solving for or varying the discount rate shift the whole curve.
+func WithDiscountCurve(spreads []float64) Option {
+	return func(s *settings) {
+		s.curve = spreads
+	}
+}
+
+// StubPeriod calculates the share of the fiscal year remaining at the valuation date, for use with WithStub.
+//
+// Arguments:
+//
+//	fyEnd: The end date of the latest reported fiscal year.
+//	valuationDate: The date of the valuation.
+//
+// Returns:
+//
+//	The share of the year from the valuation date to the next fiscal year end.
+//	An error, if any.
+func StubPeriod(fyEnd time.Time, valuationDate time.Time) (float64, error) {
+	elapsed := valuationDate.Sub(fyEnd).Hours() / 24 / 365.25
+	if elapsed < 0 {
+		return 0, fmt.Errorf("valuation date must not be before the fiscal year end")
+	}
+	if elapsed >= 1 {
+		return 0, fmt.Errorf(
+			"fiscal year end is more than a year before the valuation date, the reported data is stale",
+		)
+	}
+
+	return 1 - elapsed, nil
+}
+
+func newSettings(opts []Option) *settings {
+	s := &settings{stub: 1}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// presentValue discounts a year's cash flow to the valuation date,
+// and records the share of it attributable to shares issued by then.
+func (s *settings) presentValue(cashFlow float64, discountRate float64, year int) float64 {
+	discountFactor := s.discountFactor(discountRate, year)
+
+	if s.breakdown != nil {
+		s.breakdown.DiscountRates = append(s.breakdown.DiscountRates, s.rate(discountRate, year))
+		s.breakdown.DiscountFactors = append(s.breakdown.DiscountFactors, 1/discountFactor)
+	}
+
+	presentValue := cashFlow * s.cashFlowShare(year) / discountFactor
+	s.dilute(presentValue, year)
+
+	return presentValue
+}
+
+// terminalPresentValue discounts a terminal value at the end of a year to the valuation date,
+// and records the share of it attributable to shares issued by then.
+func (s *settings) terminalPresentValue(terminalValue float64, discountRate float64, year int) float64 {
+	presentValue := terminalValue / s.terminalDiscountFactor(discountRate, year)
+	s.dilute(presentValue, year)
+
+	return presentValue
+}
+
+// dilute records the share of a present value that is attributable to the shares issued by the end of a year.
+func (s *settings) dilute(presentValue float64, year int) {
+	s.dilution += presentValue - presentValue/s.shareFactor(year)
+}
+
+// cashFlowShare returns the share of a year's cash flow that is received after the valuation date.
+func (s *settings) cashFlowShare(year int) float64 {
+	if year == 1 {
+		return s.stub
+	}
+	return 1
+}
+
+// rate returns the discount rate in a year, after any spread from the discount curve.
+func (s *settings) rate(discountRate float64, year int) float64 {
+	if year <= len(s.curve) {
+		return discountRate + s.curve[year-1]
+	}
+	return discountRate
+}
+
+// discountFactor returns the factor that discounts a year's cash flow to the valuation date.
+func (s *settings) discountFactor(discountRate float64, year int) float64 {
+	if len(s.curve) > 0 {
+		elapsed := s.cashFlowShare(year)
+		if s.midYear {
+			elapsed /= 2
+		}
+		return s.curveFactor(discountRate, year, elapsed)
+	}
+
+	period := float64(year) - 1 + s.stub
+	if s.midYear {
+		if year == 1 {
+			period -= s.stub / 2
+		} else {
+			period -= 0.5
+		}
+	}
+
+	return math.Pow(1+discountRate, period)
+}
+
+// terminalDiscountFactor returns the factor that discounts a value at the end of a year to the valuation date.
+func (s *settings) terminalDiscountFactor(discountRate float64, year int) float64 {
+	if len(s.curve) > 0 {
+		return s.curveFactor(discountRate, year, s.cashFlowShare(year))
+	}
+	return math.Pow(1+discountRate, float64(year)-1+s.stub)
+}
+
+// curveFactor compounds each year's rate on the discount curve, from the valuation date to a point elapsed (in years) into a year.
+// The length of each year is the share of it after the valuation date, i.e. the first year is the stub.
+func (s *settings) curveFactor(discountRate float64, year int, elapsed float64) float64 {
+	factor := 1.0
+	for i := 1; i < year; i++ {
+		factor *= math.Pow(1+s.rate(discountRate, i), s.cashFlowShare(i))
+	}
+
+	return factor * math.Pow(1+s.rate(discountRate, year), elapsed)
+}
+
+// shareFactor returns the projected share count in a year, relative to the current share count.
+// A shrinking share count is ignored, as the buybacks are paid for from the cash flows.
+func (s *settings) shareFactor(year int) float64 {
+	if s.shareChange <= 0 {
+		return 1
+	}
+	return math.Pow(1+s.shareChange, float64(year))
+}
+
+// terminal records the present value of a terminal value, with the exit multiple and perpetual growth rate it represents.
+func (s *settings) terminal(pvTerminalValue float64, exitMultiple float64, growthRate float64) {
+	if s.breakdown != nil {
+		s.breakdown.TerminalValue = pvTerminalValue
+		s.breakdown.TerminalMultiple = exitMultiple
+		s.breakdown.TerminalGrowthRate = growthRate
+	}
+}
+
+// perShare converts the total present value of a model into a value per share, applying the equity bridge and dilution if configured.
+func (s *settings) perShare(totalValue float64, sharesOutstanding int) float64 {
+	equityValue := totalValue - s.dilution
+	if s.bridge != nil {
+		equityValue -= float64(s.bridge.NetDebt + s.bridge.MinorityInterest + s.bridge.PreferredEquity)
+	}
+
+	if s.breakdown != nil {
+		s.breakdown.EnterpriseValue = totalValue
+		s.breakdown.EquityValue = equityValue
+		s.breakdown.Dilution = s.dilution
+
+		// a share of a total value that isn't positive is meaningless
+		s.breakdown.TerminalShare = math.NaN()
+		if totalValue > 0 {
+			s.breakdown.TerminalShare = s.breakdown.TerminalValue / totalValue
+		}
+	}
+
+	return equityValue / float64(sharesOutstanding)
+}
@@ -0,0 +1,274 @@
+package calc
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_WithEquityBridge(t *testing.T) {
+	bridge := EquityBridge{
+		NetDebt:          49000000000,
+		MinorityInterest: 1000000000,
+		PreferredEquity:  500000000,
+	}
+
+	var breakdown Breakdown
+
+	dcf, _, err := DCFTwoStage(
+		fcfHistory[0],
+		growthRate,
+		perpetualGrowthRate,
+		highGrowthYears,
+		shares,
+		discountRate,
+		WithEquityBridge(bridge),
+		WithBreakdown(&breakdown),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the enterprise value is unchanged by the bridge, the equity value is net of the claims
+	if math.Abs(breakdown.EnterpriseValue/float64(shares)-156.31884569425605) > 1e-9 ||
+		math.Abs(breakdown.EnterpriseValue-breakdown.EquityValue-50500000000) > 1 {
+		fmt.Println(breakdown)
+		t.Fatalf(`DCFTwoStage(..., WithEquityBridge(%+v)) breakdown = %+v`, bridge, breakdown)
+	}
+
+	if dcf != breakdown.EquityValue/float64(shares) {
+		fmt.Println(dcf)
+		t.Fatalf(`DCFTwoStage(..., WithEquityBridge(%+v)) = %f`, bridge, dcf)
+	}
+}
+
+func Test_UnleveredFCF(t *testing.T) {
+	unlevered, err := UnleveredFCF([]int{100, 200}, []int{10, 20}, 0.25)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(unlevered, []int{107, 215}) {
+		fmt.Println(unlevered)
+		t.Fatalf(`UnleveredFCF(%v, %v, %f) = %v`, []int{100, 200}, []int{10, 20}, 0.25, unlevered)
+	}
+}
+
+func Test_WithShareChange(t *testing.T) {
+	shareChange := -0.03
+
+	dcf, _, err := DCFTwoStage(
+		fcfHistory[0],
+		growthRate,
+		perpetualGrowthRate,
+		highGrowthYears,
+		shares,
+		discountRate,
+		WithShareChange(shareChange),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// buybacks are paid for from the FCF, so a shrinking share count doesn't add value on top
+	if dcf != 156.31884569425605 {
+		fmt.Println(dcf)
+		t.Fatalf(`DCFTwoStage(..., WithShareChange(%f)) = %f`, shareChange, dcf)
+	}
+
+	// growing total FCF with the share count leaves the per share cash flows, and value, unchanged
+	var breakdown Breakdown
+
+	dilutedGrowth := (1+growthRate)*(1+0.05) - 1
+	diluted, _, err := DCFTwoStage(
+		fcfHistory[0],
+		dilutedGrowth,
+		perpetualGrowthRate,
+		highGrowthYears,
+		shares,
+		discountRate,
+		WithShareChange(0.05),
+		WithBreakdown(&breakdown),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if math.Abs(diluted-156.31884569425605) > 1e-9 {
+		fmt.Println(diluted)
+		t.Fatalf(`DCFTwoStage(%f, ..., WithShareChange(%f)) = %f`, dilutedGrowth, 0.05, diluted)
+	}
+
+	// the enterprise value is the whole present value, the dilution is deducted on the way to equity value
+	if math.Abs(breakdown.EnterpriseValue-breakdown.Dilution-breakdown.EquityValue) > 1 ||
+		breakdown.EnterpriseValue/float64(shares) <= diluted {
+		fmt.Println(breakdown)
+		t.Fatalf(`DCFTwoStage(..., WithShareChange(%f)) breakdown = %+v`, 0.05, breakdown)
+	}
+}
+
+func Test_WithMidYear(t *testing.T) {
+	dcf, _, err := DCFTwoStage(100, 0, 0, 1, 1, 0.1, WithMidYear())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the year's cash flow is discounted half a year, the terminal value a full year
+	expected := 100/math.Sqrt(1.1) + 1000/1.1
+	if math.Abs(dcf-expected) > 1e-9 {
+		fmt.Println(dcf)
+		t.Fatalf(`DCFTwoStage(..., WithMidYear()) = %f, expected %f`, dcf, expected)
+	}
+}
+
+func Test_WithStub(t *testing.T) {
+	dcf, _, err := DCFTwoStage(100, 0, 0, 1, 1, 0.1, WithStub(0.5))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// half of the year's cash flow remains, and everything is half a year closer
+	expected := (50 + 1000) / math.Sqrt(1.1)
+	if math.Abs(dcf-expected) > 1e-9 {
+		fmt.Println(dcf)
+		t.Fatalf(`DCFTwoStage(..., WithStub(0.5)) = %f, expected %f`, dcf, expected)
+	}
+
+	fyEnd := time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC)
+	stub, err := StubPeriod(fyEnd, fyEnd.AddDate(0, 6, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if math.Abs(stub-0.5) > 0.01 {
+		t.Fatalf(`StubPeriod(%s, %s) = %f`, fyEnd, fyEnd.AddDate(0, 6, 0), stub)
+	}
+
+	if _, err := StubPeriod(fyEnd, fyEnd.AddDate(1, 1, 0)); err == nil {
+		t.Fatalf(`StubPeriod should reject a fiscal year end more than a year ago`)
+	}
+}
+
+func Test_WithDiscountCurve(t *testing.T) {
+	var breakdown Breakdown
+
+	dcf, _, err := DCFTwoStage(
+		100,
+		0,
+		0,
+		2,
+		1,
+		0.1,
+		WithDiscountCurve([]float64{0.1, 0.05}),
+		WithBreakdown(&breakdown),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the terminal value is discounted through the curve, then valued at the discount rate
+	expected := 100/1.2 + 100/(1.2*1.15) + 1000/(1.2*1.15)
+	if math.Abs(dcf-expected) > 1e-9 {
+		fmt.Println(dcf)
+		t.Fatalf(`DCFTwoStage(..., WithDiscountCurve(...)) = %f, expected %f`, dcf, expected)
+	}
+
+	if !reflect.DeepEqual(breakdown.DiscountRates, []float64{0.2, 0.15000000000000002}) ||
+		math.Abs(breakdown.DiscountFactors[1]-1/(1.2*1.15)) > 1e-9 {
+		fmt.Println(breakdown)
+		t.Fatalf(`DCFTwoStage(..., WithDiscountCurve(...)) breakdown = %+v`, breakdown)
+	}
+
+	// a flat curve is the same as a single discount rate
+	flat, _, err := DCFTwoStage(
+		fcfHistory[0],
+		growthRate,
+		perpetualGrowthRate,
+		highGrowthYears,
+		shares,
+		discountRate,
+		WithDiscountCurve(make([]float64, highGrowthYears)),
+		WithMidYear(),
+		WithStub(0.5),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	single, _, err := DCFTwoStage(
+		fcfHistory[0],
+		growthRate,
+		perpetualGrowthRate,
+		highGrowthYears,
+		shares,
+		discountRate,
+		WithMidYear(),
+		WithStub(0.5),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if math.Abs(flat-single) > 1e-9 {
+		t.Fatalf(`DCFTwoStage(..., WithDiscountCurve(flat)) = %f, expected %f`, flat, single)
+	}
+
+	_, _, err = DDMHModel(100, 0.1, 0.02, 5, 1, 0.08, WithDiscountCurve([]float64{0.01}))
+	if err == nil {
+		t.Fatalf(`DDMHModel should reject a discount curve`)
+	}
+}
+
+func Test_DDMTwoStageOptions(t *testing.T) {
+	tests := []struct {
+		name     string
+		numYears int
+		opts     []Option
+		expected float64
+	}{
+		{"WithMidYear()", 1, []Option{WithMidYear()}, 100/math.Sqrt(1.1) + 1000/1.1},
+		{"WithStub(0.5)", 1, []Option{WithStub(0.5)}, (50 + 1000) / math.Sqrt(1.1)},
+		{
+			"WithDiscountCurve(...)",
+			2,
+			[]Option{WithDiscountCurve([]float64{0.1, 0.05})},
+			100/1.2 + 100/(1.2*1.15) + 1000/(1.2*1.15),
+		},
+	}
+
+	for _, test := range tests {
+		// the conventions reach the terminal value of a DDM, as they do for a DCF
+		ddm, _, err := DDMTwoStage(100, 0, 0, test.numYears, 1, 0.1, test.opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if math.Abs(ddm-test.expected) > 1e-9 {
+			fmt.Println(ddm)
+			t.Fatalf(`DDMTwoStage(..., %s) = %f, expected %f`, test.name, ddm, test.expected)
+		}
+	}
+}
+
+func Test_TerminalShareNonPositive(t *testing.T) {
+	var breakdown Breakdown
+
+	_, err := DCFTurnaround(
+		TurnaroundInputs{CurrentFCF: -1000, TurnaroundYear: 5, TargetFCF: 1, PerpetualGrowthRate: 0.02},
+		5,
+		1,
+		0.1,
+		WithBreakdown(&breakdown),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the cash burn outweighs the terminal value, so its share of the total is meaningless
+	if breakdown.EnterpriseValue >= 0 || !math.IsNaN(breakdown.TerminalShare) {
+		fmt.Println(breakdown)
+		t.Fatalf(`DCFTurnaround(...) breakdown = %+v`, breakdown)
+	}
+}
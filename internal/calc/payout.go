@@ -0,0 +1,173 @@
+package calc
+
+import (
+	"fmt"
+	"math"
+)
+
+// PayoutInputs holds the drivers of a DDM where dividends are derived from earnings and a changing payout ratio.
+type PayoutInputs struct {
+	// CurrentEarnings is the current net income.
+	CurrentEarnings int
+	// GrowthRate is the annual growth rate of the earnings during the high-growth stage.
+	GrowthRate float64
+	// PerpetualGrowthRate is the perpetual growth rate of the earnings once the company is mature.
+	PerpetualGrowthRate float64
+	// CurrentPayout is the share of earnings paid as dividends today, kept during the high-growth stage.
+	CurrentPayout float64
+	// MaturePayout is the share of earnings paid as dividends once the company is mature.
+	MaturePayout float64
+}
+
+// PayoutProjection is a projected year of a payout transition DDM.
+type PayoutProjection struct {
+	Earnings    int
+	PayoutRatio float64
+	Dividends   int
+}
+
+// DDMPayoutTransition calculates a multi-stage DDM, where the dividends are projected from earnings and a payout ratio.
+// During the high-growth stage, earnings grow at the growth rate at the current payout. During the transition stage,
+// growth fades linearly to the perpetual rate as the payout rises (or falls) linearly to the mature payout.
+// Unlike compounding the dividend alone, dividends grow faster than earnings while the payout rises.
+//
+// Arguments:
+//
+//	inputs: The drivers of the projection.
+//	highGrowthYears: The number of years in the high-growth stage.
+//	transitionYears: The number of years in the transition stage.
+//	sharesOutstanding: The number of shares outstanding.
+//	discountRate: The discount rate to use for the present value calculation.
+//	opts: Optional settings, e.g. WithMidYear, WithStub or WithDiscountCurve.
+//
+// Returns:
+//
+//	The intrinsic value of the company.
+//	The projected earnings, payout ratios and dividends.
+//	An error, if any.
+func DDMPayoutTransition(
+	inputs PayoutInputs,
+	highGrowthYears int,
+	transitionYears int,
+	sharesOutstanding int,
+	discountRate float64,
+	opts ...Option,
+) (float64, []PayoutProjection, error) {
+	if sharesOutstanding <= 0 {
+		return 0, nil, fmt.Errorf("number of shares outstanding must be greater than zero")
+	}
+	if discountRate <= inputs.PerpetualGrowthRate {
+		return 0, nil, fmt.Errorf("discount rate must be greater than the perpetual growth rate")
+	}
+	if transitionYears < 0 {
+		return 0, nil, fmt.Errorf("number of transition years must not be negative")
+	}
+	if highGrowthYears+transitionYears <= 0 {
+		return 0, nil, fmt.Errorf("number of projected years must be greater than zero")
+	}
+
+	s := newSettings(opts)
+
+	var projections []PayoutProjection
+
+	totalValue := 0.0
+	earnings := float64(inputs.CurrentEarnings)
+
+	project := func(year int, growthRate float64, payout float64) float64 {
+		earnings *= 1 + growthRate
+		dividends := earnings * payout
+
+		projections = append(projections, PayoutProjection{
+			Earnings:    int(earnings),
+			PayoutRatio: payout,
+			Dividends:   int(dividends),
+		})
+		totalValue += s.presentValue(dividends, discountRate, year)
+
+		return dividends
+	}
+
+	// high growth phase, at the current payout
+	dividends := 0.0
+	for i := 1; i <= highGrowthYears; i++ {
+		dividends = project(i, inputs.GrowthRate, inputs.CurrentPayout)
+	}
+
+	// transition phase, growth fades to the perpetual rate as the payout converges to the mature payout
+	for i := 1; i <= transitionYears; i++ {
+		share := float64(i) / float64(transitionYears)
+		fadedGrowthRate := inputs.GrowthRate - (inputs.GrowthRate-inputs.PerpetualGrowthRate)*share
+		payout := inputs.CurrentPayout + (inputs.MaturePayout-inputs.CurrentPayout)*share
+		dividends = project(highGrowthYears+i, fadedGrowthRate, payout)
+	}
+
+	// stable growth phase, at the mature payout
+	numYears := highGrowthYears + transitionYears
+	terminalDividends := earnings * (1 + inputs.PerpetualGrowthRate) * inputs.MaturePayout
+	terminalValue := terminalDividends / (discountRate - inputs.PerpetualGrowthRate)
+	pvTerminalValue := s.terminalPresentValue(terminalValue, discountRate, numYears)
+	s.terminal(pvTerminalValue, terminalValue/dividends, inputs.PerpetualGrowthRate)
+
+	totalValue += pvTerminalValue
+
+	// per share value
+	intrinsicValue := s.perShare(totalValue, sharesOutstanding)
+
+	return intrinsicValue, projections, nil
+}
+
+// PayoutRatio calculates the share of earnings paid as dividends over the whole history, so that a single year
+// of depressed earnings doesn't distort it.
+//
+// Arguments:
+//
+//	dividends: An array of type int with the dividends paid.
+//	earnings: An array of type int with the net income for the same periods.
+//
+// Returns:
+//
+//	The payout ratio.
+//	An error, if any.
+func PayoutRatio(dividends []int, earnings []int) (float64, error) {
+	if len(dividends) != len(earnings) {
+		return 0, fmt.Errorf(
+			"dividends and earnings must cover the same periods - check input: %v, %v",
+			dividends,
+			earnings,
+		)
+	}
+
+	var totalDividends, totalEarnings float64
+	for i := range dividends {
+		totalDividends += float64(dividends[i])
+		totalEarnings += float64(earnings[i])
+	}
+
+	if totalEarnings <= 0 {
+		return 0, fmt.Errorf(
+			"earnings must be positive to calculate a payout ratio - check input: %v",
+			earnings,
+		)
+	}
+
+	return totalDividends / totalEarnings, nil
+}
+
+// SustainablePayout calculates the payout ratio at which a company can sustain a growth rate from its retained earnings,
+// i.e. 1 - growth / ROE, clamped between 0 and 1.
+//
+// Arguments:
+//
+//	growthRate: The growth rate to sustain.
+//	roe: The return on equity of the retained earnings.
+//
+// Returns:
+//
+//	The sustainable payout ratio.
+func SustainablePayout(growthRate float64, roe float64) float64 {
+	if roe <= 0 {
+		return 0
+	}
+
+	return math.Max(0, math.Min(1, 1-growthRate/roe))
+}
@@ -0,0 +1,63 @@
+package calc
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func Test_DDMPayoutTransition(t *testing.T) {
+	inputs := PayoutInputs{
+		CurrentEarnings:     100,
+		GrowthRate:          0.1,
+		PerpetualGrowthRate: 0.02,
+		CurrentPayout:       0.2,
+		MaturePayout:        0.6,
+	}
+
+	value, projections, err := DDMPayoutTransition(inputs, 1, 2, 1, 0.1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// dividends grow faster than earnings as the payout rises
+	expectedDividends := []int{22, 46, 71}
+	for i, p := range projections {
+		if p.Dividends != expectedDividends[i] {
+			fmt.Println(projections)
+			t.Fatalf(`DDMPayoutTransition(%+v, ...) projections = %+v`, inputs, projections)
+		}
+	}
+
+	if math.Abs(projections[2].PayoutRatio-0.6) > 1e-9 {
+		t.Fatalf(`DDMPayoutTransition(%+v, ...) mature payout = %f`, inputs, projections[2].PayoutRatio)
+	}
+
+	earnings := 110 * 1.06 * 1.02
+	expected := 22/1.1 + 110*1.06*0.4/1.21 + earnings*0.6/1.331 + earnings*1.02*0.6/0.08/1.331
+	if math.Abs(value-expected) > 1e-9 {
+		fmt.Println(value)
+		t.Fatalf(`DDMPayoutTransition(%+v, ...) = %f, expected %f`, inputs, value, expected)
+	}
+}
+
+func Test_PayoutRatio(t *testing.T) {
+	payout, err := PayoutRatio([]int{10, 30}, []int{100, 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if payout != 0.2 {
+		t.Fatalf(`PayoutRatio(...) = %f`, payout)
+	}
+
+	if _, err := PayoutRatio([]int{10}, []int{-100}); err == nil {
+		t.Fatalf(`PayoutRatio should reject negative earnings`)
+	}
+}
+
+func Test_SustainablePayout(t *testing.T) {
+	if payout := SustainablePayout(0.03, 0.12); math.Abs(payout-0.75) > 1e-9 {
+		t.Fatalf(`SustainablePayout(0.03, 0.12) = %f`, payout)
+	}
+}
@@ -0,0 +1,91 @@
+package calc
+
+import (
+	"fmt"
+	"math"
+)
+
+// grahamMultiplier is Graham's maximum P/E (15) times his maximum P/B (1.5).
+const grahamMultiplier = 22.5
+
+// grahamBondYield is the AAA corporate bond yield (in percent) when Graham revised his formula.
+const grahamBondYield = 4.4
+
+// GrahamNumber calculates the Graham number, the most a defensive investor should pay per share,
+// i.e. the square root of 22.5 × EPS × book value per share.
+//
+// Arguments:
+//
+//	eps: The earnings per share.
+//	bookValuePerShare: The book value of equity per share.
+//
+// Returns:
+//
+//	The Graham number.
+//	An error, if any.
+func GrahamNumber(eps float64, bookValuePerShare float64) (float64, error) {
+	if eps <= 0 {
+		return 0, fmt.Errorf("EPS must be greater than zero")
+	}
+	if bookValuePerShare <= 0 {
+		return 0, fmt.Errorf("book value per share must be greater than zero")
+	}
+
+	return math.Sqrt(grahamMultiplier * eps * bookValuePerShare), nil
+}
+
+// RevisedGraham calculates the value per share from Graham's revised formula, EPS × (8.5 + 2g) × 4.4 / Y,
+// where g is the growth rate and Y the bond yield, both in percent.
+//
+// Arguments:
+//
+//	eps: The earnings per share.
+//	growthRate: The expected annual growth rate of the earnings.
+//	bondYield: The current bond yield, e.g. the risk-free rate.
+//
+// Returns:
+//
+//	The intrinsic value per share.
+//	An error, if any.
+func RevisedGraham(eps float64, growthRate float64, bondYield float64) (float64, error) {
+	if bondYield <= 0 {
+		return 0, fmt.Errorf("bond yield must be greater than zero")
+	}
+
+	return eps * (8.5 + 2*growthRate*100) * grahamBondYield / (bondYield * 100), nil
+}
+
+// LynchFairValue calculates Lynch's fair value per share, where a fairly priced company has a PEG of 1,
+// i.e. a P/E equal to its growth rate plus its dividend yield, both in percent.
+//
+// Arguments:
+//
+//	eps: The earnings per share.
+//	growthRate: The expected annual growth rate of the earnings.
+//	dividendYield: The current dividend yield.
+//
+// Returns:
+//
+//	The fair value per share.
+func LynchFairValue(eps float64, growthRate float64, dividendYield float64) float64 {
+	return eps * (growthRate + dividendYield) * 100
+}
+
+// EarningsYield calculates the earnings yield, the inverse of the P/E ratio.
+//
+// Arguments:
+//
+//	eps: The earnings per share.
+//	price: The current price per share.
+//
+// Returns:
+//
+//	The earnings yield.
+//	An error, if any.
+func EarningsYield(eps float64, price float64) (float64, error) {
+	if price <= 0 {
+		return 0, fmt.Errorf("price must be greater than zero")
+	}
+
+	return eps / price, nil
+}
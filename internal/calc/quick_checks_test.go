@@ -0,0 +1,60 @@
+package calc
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func Test_GrahamNumber(t *testing.T) {
+	value, err := GrahamNumber(2, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if math.Abs(value-30) > 1e-9 {
+		fmt.Println(value)
+		t.Fatalf(`GrahamNumber(2, 20) = %f, expected 30`, value)
+	}
+
+	if _, err := GrahamNumber(-1, 20); err == nil {
+		t.Fatalf(`GrahamNumber should reject negative EPS`)
+	}
+}
+
+func Test_RevisedGraham(t *testing.T) {
+	value, err := RevisedGraham(2, 0.05, 0.044)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// at Graham's bond yield, the formula reduces to EPS × (8.5 + 2g)
+	if math.Abs(value-37) > 1e-9 {
+		fmt.Println(value)
+		t.Fatalf(`RevisedGraham(2, 0.05, 0.044) = %f, expected 37`, value)
+	}
+
+	if _, err := RevisedGraham(2, 0.05, 0); err == nil {
+		t.Fatalf(`RevisedGraham should reject a bond yield of zero`)
+	}
+}
+
+func Test_LynchFairValue(t *testing.T) {
+	value := LynchFairValue(2, 0.12, 0.03)
+	if math.Abs(value-30) > 1e-9 {
+		fmt.Println(value)
+		t.Fatalf(`LynchFairValue(2, 0.12, 0.03) = %f, expected 30`, value)
+	}
+}
+
+func Test_EarningsYield(t *testing.T) {
+	value, err := EarningsYield(2, 25)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if math.Abs(value-0.08) > 1e-9 {
+		fmt.Println(value)
+		t.Fatalf(`EarningsYield(2, 25) = %f, expected 0.08`, value)
+	}
+}
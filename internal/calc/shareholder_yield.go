@@ -0,0 +1,93 @@
+package calc
+
+import "fmt"
+
+// ShareholderYield is the cash returned to shareholders as a share of the market cap.
+type ShareholderYield struct {
+	// DividendYield is the cash paid for dividends.
+	DividendYield float64
+	// BuybackYield is the cash paid to repurchase shares.
+	BuybackYield float64
+	// IssuanceYield is the cash raised by issuing shares, e.g. from stock-based compensation.
+	IssuanceYield float64
+	// TotalYield is the dividend yield plus the buyback yield, net of issuance.
+	TotalYield float64
+}
+
+// ShareholderPayouts calculates the total cash returned to shareholders for each period,
+// i.e. the dividends plus the share repurchases, net of the shares issued.
+//
+// Arguments:
+//
+//	dividends: An array of type int with the cash paid for dividends.
+//	repurchased: An array of type int with the cash paid to repurchase shares for the same periods.
+//	issued: An array of type int with the cash raised by issuing shares for the same periods.
+//
+// Returns:
+//
+//	The total shareholder payouts.
+//	An error, if any.
+func ShareholderPayouts(dividends []int, repurchased []int, issued []int) ([]int, error) {
+	if len(dividends) != len(repurchased) || len(dividends) != len(issued) {
+		return nil, fmt.Errorf(
+			"dividends, repurchases and issuance must cover the same periods - check input: %v, %v, %v",
+			dividends,
+			repurchased,
+			issued,
+		)
+	}
+
+	payouts := make([]int, len(dividends))
+	for i := range dividends {
+		payouts[i] = dividends[i] + repurchased[i] - issued[i]
+	}
+
+	return payouts, nil
+}
+
+// ShareholderYields calculates the average historical dividend, buyback and issuance yields on the current market cap.
+//
+// Arguments:
+//
+//	dividends: An array of type int with the cash paid for dividends.
+//	repurchased: An array of type int with the cash paid to repurchase shares for the same periods.
+//	issued: An array of type int with the cash raised by issuing shares for the same periods.
+//	marketCap: The current market capitalization.
+//
+// Returns:
+//
+//	The shareholder yields.
+//	An error, if any.
+func ShareholderYields(
+	dividends []int,
+	repurchased []int,
+	issued []int,
+	marketCap float64,
+) (ShareholderYield, error) {
+	if marketCap <= 0 {
+		return ShareholderYield{}, fmt.Errorf("market cap must be greater than zero")
+	}
+	if _, err := ShareholderPayouts(dividends, repurchased, issued); err != nil {
+		return ShareholderYield{}, err
+	}
+
+	yield := func(values []int) float64 {
+		var total float64
+		for _, v := range values {
+			total += float64(v)
+		}
+		if len(values) == 0 {
+			return 0
+		}
+		return total / float64(len(values)) / marketCap
+	}
+
+	y := ShareholderYield{
+		DividendYield: yield(dividends),
+		BuybackYield:  yield(repurchased),
+		IssuanceYield: yield(issued),
+	}
+	y.TotalYield = y.DividendYield + y.BuybackYield - y.IssuanceYield
+
+	return y, nil
+}
@@ -0,0 +1,87 @@
+package calc
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func Test_ShareholderPayouts(t *testing.T) {
+	payouts, err := ShareholderPayouts([]int{10, 12}, []int{50, 0}, []int{5, 20})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// net issuance in a year without buybacks reduces the payout
+	expected := []int{55, -8}
+	for i := range expected {
+		if payouts[i] != expected[i] {
+			fmt.Println(payouts)
+			t.Fatalf(`ShareholderPayouts(...) = %v, expected %v`, payouts, expected)
+		}
+	}
+
+	if _, err := ShareholderPayouts([]int{10}, []int{50, 0}, []int{5}); err == nil {
+		t.Fatalf(`ShareholderPayouts should reject mismatched periods`)
+	}
+}
+
+func Test_ShareholderYields(t *testing.T) {
+	yields, err := ShareholderYields([]int{10, 30}, []int{40, 60}, []int{10, 10}, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := ShareholderYield{
+		DividendYield: 0.02,
+		BuybackYield:  0.05,
+		IssuanceYield: 0.01,
+		TotalYield:    0.06,
+	}
+	if math.Abs(yields.DividendYield-expected.DividendYield) > 1e-9 ||
+		math.Abs(yields.BuybackYield-expected.BuybackYield) > 1e-9 ||
+		math.Abs(yields.IssuanceYield-expected.IssuanceYield) > 1e-9 ||
+		math.Abs(yields.TotalYield-expected.TotalYield) > 1e-9 {
+		fmt.Println(yields)
+		t.Fatalf(`ShareholderYields(...) = %+v, expected %+v`, yields, expected)
+	}
+
+	if _, err := ShareholderYields([]int{10}, []int{40}, []int{10}, 0); err == nil {
+		t.Fatalf(`ShareholderYields should reject a market cap of zero`)
+	}
+}
+
+func Test_ShareholderPayoutsTerminalValue(t *testing.T) {
+	payouts, err := ShareholderPayouts([]int{40, 40}, []int{60, 80}, []int{0, 20})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var breakdown Breakdown
+
+	value, _, err := DDMTwoStage(
+		payouts[len(payouts)-1],
+		0.05,
+		0.02,
+		5,
+		10,
+		0.08,
+		WithBreakdown(&breakdown),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the terminal value of the payouts is discounted like any other cash flow, not added at face value
+	terminalValue := 100 * math.Pow(1.05, 5) * 1.02 / 0.06
+	expected := terminalValue / math.Pow(1.08, 5)
+	if math.Abs(breakdown.TerminalValue-expected) > 1e-6 || value*10 >= terminalValue {
+		fmt.Println(breakdown)
+		t.Fatalf(
+			`DDMTwoStage(%d, ...) terminal value = %f, expected %f`,
+			payouts[1],
+			breakdown.TerminalValue,
+			expected,
+		)
+	}
+}
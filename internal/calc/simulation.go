@@ -0,0 +1,237 @@
+package calc
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// Distribution is a source of randomly sampled model inputs for a Monte Carlo simulation.
+type Distribution interface {
+	Sample(rng *rand.Rand) float64
+}
+
+// Constant is a distribution that always samples the same value.
+type Constant float64
+
+func (c Constant) Sample(_ *rand.Rand) float64 {
+	return float64(c)
+}
+
+// Normal is a normal distribution with a mean and standard deviation.
+type Normal struct {
+	Mean   float64
+	StdDev float64
+}
+
+func (n Normal) Sample(rng *rand.Rand) float64 {
+	return n.Mean + rng.NormFloat64()*n.StdDev
+}
+
+// Triangular is a triangular distribution between a minimum and maximum, peaking at the mode.
+type Triangular struct {
+	Min  float64
+	Mode float64
+	Max  float64
+}
+
+func (t Triangular) Sample(rng *rand.Rand) float64 {
+	if t.Max == t.Min {
+		return t.Min
+	}
+
+	u := rng.Float64()
+	cut := (t.Mode - t.Min) / (t.Max - t.Min)
+
+	if u < cut {
+		return t.Min + math.Sqrt(u*(t.Max-t.Min)*(t.Mode-t.Min))
+	}
+
+	return t.Max - math.Sqrt((1-u)*(t.Max-t.Min)*(t.Max-t.Mode))
+}
+
+// Uniform is a uniform distribution between a minimum and maximum.
+type Uniform struct {
+	Min float64
+	Max float64
+}
+
+func (u Uniform) Sample(rng *rand.Rand) float64 {
+	return u.Min + rng.Float64()*(u.Max-u.Min)
+}
+
+// Bootstrap is a distribution of the mean of historical observations, resampled with replacement.
+type Bootstrap []float64
+
+func (b Bootstrap) Sample(rng *rand.Rand) float64 {
+	if len(b) == 0 {
+		return 0
+	}
+
+	sum := 0.0
+	for range b {
+		sum += b[rng.Intn(len(b))]
+	}
+
+	return sum / float64(len(b))
+}
+
+// SimulationInputs holds the distributions sampled for each run of a Monte Carlo simulation.
+type SimulationInputs struct {
+	CurrentFCF   Distribution
+	GrowthRate   Distribution
+	Terminal     Distribution
+	DiscountRate Distribution
+}
+
+// Simulation holds the fair values produced by a Monte Carlo simulation.
+type Simulation struct {
+	// Values are the fair values of each successful run, sorted in ascending order.
+	Values []float64
+	// Failed is the number of runs rejected by the model, e.g. a discount rate below the perpetual growth rate.
+	Failed int
+}
+
+// HistogramBin is a range of fair values and the number of simulated values that fell in it.
+type HistogramBin struct {
+	Lower float64
+	Upper float64
+	Count int
+}
+
+// MonteCarlo runs a valuation model many times with inputs sampled from distributions.
+//
+// Arguments:
+//
+//	runs: The number of times to run the model.
+//	seed: The seed for the random number generator, so that runs are reproducible.
+//	inputs: The distributions of the model inputs.
+//	valueAt: The valuation model, returning the intrinsic value per share for a set of inputs.
+//
+// Returns:
+//
+//	The simulated fair values.
+//	An error, if any.
+func MonteCarlo(
+	runs int,
+	seed int64,
+	inputs SimulationInputs,
+	valueAt func(currentFCF int, growthRate, terminal, discountRate float64) (float64, error),
+) (Simulation, error) {
+	var sim Simulation
+
+	if runs <= 0 {
+		return sim, fmt.Errorf("number of runs must be greater than zero")
+	}
+
+	rng := rand.New(rand.NewSource(seed))
+
+	for i := 0; i < runs; i++ {
+		value, err := valueAt(
+			int(inputs.CurrentFCF.Sample(rng)),
+			inputs.GrowthRate.Sample(rng),
+			inputs.Terminal.Sample(rng),
+			inputs.DiscountRate.Sample(rng),
+		)
+		if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+			sim.Failed++
+			continue
+		}
+
+		sim.Values = append(sim.Values, value)
+	}
+
+	if len(sim.Values) == 0 {
+		return sim, fmt.Errorf("all %d runs were rejected by the model - check the input distributions", runs)
+	}
+
+	sort.Float64s(sim.Values)
+
+	return sim, nil
+}
+
+// Mean returns the mean simulated fair value.
+func (s Simulation) Mean() float64 {
+	sum := 0.0
+	for _, v := range s.Values {
+		sum += v
+	}
+
+	return sum / float64(len(s.Values))
+}
+
+// Percentile returns the simulated fair value at a percentile between 0 and 1, interpolating between values.
+func (s Simulation) Percentile(p float64) float64 {
+	n := len(s.Values)
+	if n == 0 {
+		return math.NaN()
+	}
+
+	rank := math.Max(0, math.Min(1, p)) * float64(n-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+
+	return s.Values[lower] + (rank-float64(lower))*(s.Values[upper]-s.Values[lower])
+}
+
+// ProbabilityAbove returns the share of simulated fair values that exceed a price.
+func (s Simulation) ProbabilityAbove(price float64) float64 {
+	// values are sorted, so find the first value above the price
+	i := sort.Search(len(s.Values), func(i int) bool { return s.Values[i] > price })
+
+	return float64(len(s.Values)-i) / float64(len(s.Values))
+}
+
+// Histogram buckets the simulated fair values between the 1st and 99th percentiles into equal-width bins.
+// Values in the tails are counted in the first and last bins.
+func (s Simulation) Histogram(bins int) []HistogramBin {
+	if bins <= 0 || len(s.Values) == 0 {
+		return nil
+	}
+
+	lower := s.Percentile(0.01)
+	upper := s.Percentile(0.99)
+	width := (upper - lower) / float64(bins)
+
+	histogram := make([]HistogramBin, bins)
+	for i := range histogram {
+		histogram[i].Lower = lower + float64(i)*width
+		histogram[i].Upper = lower + float64(i+1)*width
+	}
+
+	for _, v := range s.Values {
+		i := 0
+		if width > 0 {
+			i = int((v - lower) / width)
+		}
+		i = max(0, min(bins-1, i))
+		histogram[i].Count++
+	}
+
+	return histogram
+}
+
+// YoYChanges calculates the year-on-year changes of an array of ints (we assume it to be annual), skipping years that follow a zero value.
+//
+// Arguments:
+//
+//	values: An array of ints.
+//
+// Returns:
+//
+//	The year-on-year changes, as an array of float64.
+func YoYChanges(values []int) []float64 {
+	var changes []float64
+
+	for i := 1; i < len(values); i++ {
+		if values[i-1] == 0 {
+			continue
+		}
+
+		previous := float64(values[i-1])
+		changes = append(changes, (float64(values[i])-previous)/math.Abs(previous))
+	}
+
+	return changes
+}
@@ -0,0 +1,104 @@
+package calc
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func twoStageAt(currentFCF int, growthRate, terminal, rate float64) (float64, error) {
+	value, _, err := DCFTwoStage(currentFCF, growthRate, terminal, highGrowthYears, shares, rate)
+	return value, err
+}
+
+func Test_MonteCarlo_Constant(t *testing.T) {
+	inputs := SimulationInputs{
+		CurrentFCF:   Constant(float64(fcfHistory[0])),
+		GrowthRate:   Constant(growthRate),
+		Terminal:     Constant(perpetualGrowthRate),
+		DiscountRate: Constant(discountRate),
+	}
+
+	sim, err := MonteCarlo(10, 1, inputs, twoStageAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// constant inputs should reproduce the point estimate on every run
+	if sim.Percentile(0.05) != 156.31884569425605 || sim.Percentile(0.95) != 156.31884569425605 {
+		fmt.Println(sim.Values)
+		t.Fatalf(`MonteCarlo(%d, %d, %+v) = %v`, 10, 1, inputs, sim.Values)
+	}
+
+	if sim.ProbabilityAbove(150) != 1 || sim.ProbabilityAbove(160) != 0 {
+		t.Fatalf(
+			`ProbabilityAbove(150) = %f, ProbabilityAbove(160) = %f`,
+			sim.ProbabilityAbove(150),
+			sim.ProbabilityAbove(160),
+		)
+	}
+}
+
+func Test_MonteCarlo_Seeded(t *testing.T) {
+	inputs := SimulationInputs{
+		CurrentFCF:   Triangular{Min: 40000000000, Mode: 47149000000, Max: 50000000000},
+		GrowthRate:   Bootstrap(YoYChanges(fcfHistory)),
+		Terminal:     Uniform{Min: 0.01, Max: 0.03},
+		DiscountRate: Normal{Mean: 0.08, StdDev: 0.03},
+	}
+
+	first, err := MonteCarlo(1000, 42, inputs, twoStageAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := MonteCarlo(1000, 42, inputs, twoStageAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the same seed should produce the same simulation
+	if !reflect.DeepEqual(first, second) {
+		t.Fatalf(`MonteCarlo(%d, %d, %+v) is not reproducible`, 1000, 42, inputs)
+	}
+
+	// some runs sample a discount rate below the perpetual growth rate
+	if first.Failed == 0 || len(first.Values)+first.Failed != 1000 {
+		t.Fatalf(`MonteCarlo(%d, %d, %+v) failed runs = %d`, 1000, 42, inputs, first.Failed)
+	}
+
+	count := 0
+	for _, bin := range first.Histogram(10) {
+		count += bin.Count
+	}
+
+	if count != len(first.Values) {
+		t.Fatalf(`Histogram(%d) counted %d of %d values`, 10, count, len(first.Values))
+	}
+}
+
+func Test_Simulation_Percentile(t *testing.T) {
+	sim := Simulation{Values: []float64{1, 2, 3, 4, 5}}
+
+	if sim.Percentile(0.5) != 3 || sim.Percentile(0.25) != 2 || sim.Percentile(0.1) != 1.4 {
+		t.Fatalf(
+			`Percentile(0.5) = %f, Percentile(0.25) = %f, Percentile(0.1) = %f`,
+			sim.Percentile(0.5),
+			sim.Percentile(0.25),
+			sim.Percentile(0.1),
+		)
+	}
+
+	if sim.Mean() != 3 {
+		t.Fatalf(`Mean() = %f`, sim.Mean())
+	}
+}
+
+func Test_YoYChanges(t *testing.T) {
+	changes := YoYChanges([]int{100, 110, 0, 50, -50, 50})
+
+	if !reflect.DeepEqual(changes, []float64{0.1, -1, -2, 2}) {
+		fmt.Println(changes)
+		t.Fatalf(`YoYChanges(%v) = %v`, []int{100, 110, 0, 50, -50, 50}, changes)
+	}
+}
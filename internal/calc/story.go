@@ -0,0 +1,186 @@
+package calc
+
+import (
+	"fmt"
+	"math"
+)
+
+// StoryProjection is a projected year of a revenue-driven DCF, with the drivers that derive its FCF.
+type StoryProjection struct {
+	Revenue      int
+	EBIT         int
+	Reinvestment int
+	FCF          int
+}
+
+// StoryInputs holds the drivers of a revenue-driven DCF.
+type StoryInputs struct {
+	// CurrentRevenue is the revenue of the latest year, that the projection grows from.
+	CurrentRevenue int
+	// RevenueGrowth is the annual growth rate of the revenue during the projection.
+	RevenueGrowth float64
+	// CurrentMargin is the operating (EBIT) margin of the latest year.
+	CurrentMargin float64
+	// TargetMargin is the operating margin the company converges to, linearly, by the final projected year.
+	TargetMargin float64
+	// SalesToCapital is the revenue added for each unit of capital reinvested.
+	SalesToCapital float64
+	// TaxRate is the tax rate on operating income.
+	TaxRate float64
+	// PerpetualGrowthRate is the perpetual growth rate of the revenue after the projection.
+	PerpetualGrowthRate float64
+}
+
+// DCFStory calculates a revenue-driven ("story") DCF, after Damodaran's spreadsheet.
+// Rather than compounding the current FCF, each year's FCF is derived from its drivers: revenue grows, the operating margin
+// converges to a target, and growth is paid for by reinvesting capital at the sales-to-capital ratio.
+//
+// The FCF is unlevered (FCFF), so the total present value is an enterprise value. Use WithEquityBridge to arrive at equity value.
+//
+// Arguments:
+//
+//	inputs: The drivers of the projection.
+//	numYears: The number of years in the projection.
+//	sharesOutstanding: The number of shares outstanding.
+//	discountRate: The discount rate to use for the present value calculation.
+//	opts: Optional settings, e.g. WithEquityBridge or WithMidYear.
+//
+// Returns:
+//
+//	The intrinsic value of the company.
+//	The projected revenue, EBIT, reinvestment and FCF.
+//	An error, if any.
+func DCFStory(
+	inputs StoryInputs,
+	numYears int,
+	sharesOutstanding int,
+	discountRate float64,
+	opts ...Option,
+) (float64, []StoryProjection, error) {
+	if sharesOutstanding <= 0 {
+		return 0, nil, fmt.Errorf("number of shares outstanding must be greater than zero")
+	}
+	if discountRate <= inputs.PerpetualGrowthRate {
+		return 0, nil, fmt.Errorf("discount rate must be greater than the perpetual growth rate")
+	}
+	if inputs.SalesToCapital <= 0 {
+		return 0, nil, fmt.Errorf("sales-to-capital ratio must be greater than zero")
+	}
+	if numYears <= 0 {
+		return 0, nil, fmt.Errorf("number of years must be greater than zero")
+	}
+
+	s := newSettings(opts)
+
+	var (
+		projections []StoryProjection
+		projected   storyYear
+	)
+
+	totalValue := 0.0
+	revenue := float64(inputs.CurrentRevenue)
+
+	for i := 1; i <= numYears; i++ {
+		convergence := float64(i) / float64(numYears)
+		margin := inputs.CurrentMargin + (inputs.TargetMargin-inputs.CurrentMargin)*convergence
+
+		projected = inputs.project(revenue, inputs.RevenueGrowth, margin)
+		projections = append(projections, projected.round())
+		totalValue += s.presentValue(projected.fcf, discountRate, i)
+
+		revenue = projected.revenue
+	}
+
+	// stable growth phase, at the target margin, reinvesting only what's needed for perpetual growth
+	terminalYear := inputs.project(revenue, inputs.PerpetualGrowthRate, inputs.TargetMargin)
+	terminalValue := terminalYear.fcf / (discountRate - inputs.PerpetualGrowthRate)
+	pvTerminalValue := s.terminalPresentValue(terminalValue, discountRate, numYears)
+
+	// a multiple of a final year that burns cash is meaningless
+	exitMultiple := math.NaN()
+	if projected.fcf > 0 {
+		exitMultiple = terminalValue / projected.fcf
+	}
+	s.terminal(pvTerminalValue, exitMultiple, inputs.PerpetualGrowthRate)
+
+	totalValue += pvTerminalValue
+
+	// per share value
+	intrinsicValue := s.perShare(totalValue, sharesOutstanding)
+
+	return intrinsicValue, projections, nil
+}
+
+// storyYear is a projected year of a revenue-driven DCF, before rounding.
+type storyYear struct {
+	revenue      float64
+	ebit         float64
+	reinvestment float64
+	fcf          float64
+}
+
+// project derives a year's FCF from the prior year's revenue, the revenue growth and the operating margin.
+func (inputs StoryInputs) project(priorRevenue, growthRate, margin float64) storyYear {
+	revenue := priorRevenue * (1 + growthRate)
+	ebit := revenue * margin
+
+	// operating losses are not taxed
+	nopat := ebit
+	if ebit > 0 {
+		nopat *= 1 - inputs.TaxRate
+	}
+
+	reinvestment := (revenue - priorRevenue) / inputs.SalesToCapital
+
+	return storyYear{
+		revenue:      revenue,
+		ebit:         ebit,
+		reinvestment: reinvestment,
+		fcf:          nopat - reinvestment,
+	}
+}
+
+func (y storyYear) round() StoryProjection {
+	return StoryProjection{
+		Revenue:      int(math.Round(y.revenue)),
+		EBIT:         int(math.Round(y.ebit)),
+		Reinvestment: int(math.Round(y.reinvestment)),
+		FCF:          int(math.Round(y.fcf)),
+	}
+}
+
+// SalesToCapital calculates the revenue generated per unit of invested capital, over the whole history.
+//
+// Arguments:
+//
+//	revenue: An array of type int with the revenue history.
+//	investedCapital: An array of type int with the invested capital for the same periods.
+//
+// Returns:
+//
+//	The sales-to-capital ratio.
+//	An error, if any.
+func SalesToCapital(revenue []int, investedCapital []int) (float64, error) {
+	if len(revenue) != len(investedCapital) {
+		return 0, fmt.Errorf(
+			"revenue and invested capital must cover the same periods - check input: %v, %v",
+			revenue,
+			investedCapital,
+		)
+	}
+
+	var totalRevenue, totalCapital float64
+	for i := range revenue {
+		totalRevenue += float64(revenue[i])
+		totalCapital += float64(investedCapital[i])
+	}
+
+	if totalCapital <= 0 {
+		return 0, fmt.Errorf(
+			"invested capital must be positive to calculate a sales-to-capital ratio - check input: %v",
+			investedCapital,
+		)
+	}
+
+	return totalRevenue / totalCapital, nil
+}
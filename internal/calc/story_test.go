@@ -0,0 +1,82 @@
+package calc
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func Test_DCFStory(t *testing.T) {
+	inputs := StoryInputs{
+		CurrentRevenue:      1000,
+		RevenueGrowth:       0.1,
+		CurrentMargin:       0.1,
+		TargetMargin:        0.2,
+		SalesToCapital:      2,
+		TaxRate:             0.25,
+		PerpetualGrowthRate: 0.02,
+	}
+
+	value, projections, err := DCFStory(inputs, 2, 1, 0.1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the margin converges to 15% then 20%, and each 2 of added revenue costs 1 of reinvestment
+	expectedProjections := []StoryProjection{
+		{Revenue: 1100, EBIT: 165, Reinvestment: 50, FCF: 74},
+		{Revenue: 1210, EBIT: 242, Reinvestment: 55, FCF: 127},
+	}
+	if !reflect.DeepEqual(projections, expectedProjections) {
+		fmt.Println(projections)
+		t.Fatalf(`DCFStory(%+v, ...) projections = %+v`, inputs, projections)
+	}
+
+	terminalFCF := 1210*1.02*0.2*0.75 - 1210*0.02/2
+	expected := 73.75/1.1 + 126.5/(1.1*1.1) + terminalFCF/0.08/(1.1*1.1)
+	if math.Abs(value-expected) > 1e-9 {
+		fmt.Println(value)
+		t.Fatalf(`DCFStory(%+v, ...) = %f, expected %f`, inputs, value, expected)
+	}
+
+	inputs.SalesToCapital = 0
+	if _, _, err := DCFStory(inputs, 2, 1, 0.1); err == nil {
+		t.Fatalf(`DCFStory should reject a sales-to-capital ratio of zero`)
+	}
+}
+
+func Test_SalesToCapital(t *testing.T) {
+	ratio, err := SalesToCapital([]int{100, 200}, []int{50, 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ratio != 2 {
+		t.Fatalf(`SalesToCapital(...) = %f`, ratio)
+	}
+}
+
+func Test_DCFStoryNegativeFinalFCF(t *testing.T) {
+	inputs := StoryInputs{
+		CurrentRevenue:      1000,
+		RevenueGrowth:       0.5,
+		CurrentMargin:       -0.2,
+		TargetMargin:        0.01,
+		SalesToCapital:      1,
+		PerpetualGrowthRate: 0.02,
+	}
+
+	var breakdown Breakdown
+
+	_, projections, err := DCFStory(inputs, 2, 1, 0.1, WithBreakdown(&breakdown))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the reinvestment outweighs the NOPAT in the final year, so there is no meaningful exit multiple
+	if projections[1].FCF >= 0 || !math.IsNaN(breakdown.TerminalMultiple) {
+		fmt.Println(projections, breakdown)
+		t.Fatalf(`DCFStory(%+v, ...) terminal multiple = %f`, inputs, breakdown.TerminalMultiple)
+	}
+}
@@ -0,0 +1,153 @@
+package calc
+
+import (
+	"fmt"
+	"math"
+)
+
+// TurnaroundInputs holds the drivers of a turnaround DCF, for a company that is currently burning cash.
+type TurnaroundInputs struct {
+	// CurrentFCF is the current (negative) free cash flow.
+	CurrentFCF int
+	// TurnaroundYear is the first projected year of positive FCF.
+	TurnaroundYear int
+	// TargetFCF is the FCF reached in the final projected year.
+	TargetFCF int
+	// PerpetualGrowthRate is the perpetual growth rate of the FCF after the projection.
+	PerpetualGrowthRate float64
+	// Cash is the cash on hand to fund the cash burn, before any shares are issued.
+	Cash int
+	// IssuePrice is the price per share at which shares are issued to fund the cash burn beyond the cash on hand.
+	// Zero assumes the burn is funded without dilution.
+	IssuePrice float64
+	// FailureProbability is the probability that the company fails before it turns around.
+	FailureProbability float64
+	// DistressValue is the value per share if the company fails, e.g. the liquidation value.
+	DistressValue float64
+}
+
+// Turnaround holds the result of a turnaround DCF.
+type Turnaround struct {
+	// FairValue is the value per share, weighted by the probability of failure.
+	FairValue float64
+	// GoingConcernValue is the value per share if the company turns around.
+	GoingConcernValue float64
+	// Projections are the projected FCFs.
+	Projections []int
+	// CashBurn is the total negative FCF before the turnaround.
+	CashBurn int
+	// NewShares is the number of shares issued to fund the cash burn beyond the cash on hand.
+	NewShares int
+}
+
+// DCFTurnaround calculates a DCF for a company with negative FCF. Rather than compounding the negative FCF,
+// the cash burn narrows linearly until FCF turns positive in the turnaround year, then grows linearly to a target FCF.
+//
+// The cash burn reduces the value. Beyond the cash on hand, it can be funded by issuing shares, which dilutes the value per share.
+// The going-concern value is then weighted by the probability that the company fails first.
+//
+// Arguments:
+//
+//	inputs: The drivers of the turnaround.
+//	numYears: The number of years in the projection.
+//	sharesOutstanding: The number of shares outstanding.
+//	discountRate: The discount rate to use for the present value calculation.
+//	opts: Optional settings, e.g. WithMidYear or WithStub.
+//
+// Returns:
+//
+//	The result of the turnaround DCF.
+//	An error, if any.
+func DCFTurnaround(
+	inputs TurnaroundInputs,
+	numYears int,
+	sharesOutstanding int,
+	discountRate float64,
+	opts ...Option,
+) (Turnaround, error) {
+	if sharesOutstanding <= 0 {
+		return Turnaround{}, fmt.Errorf("number of shares outstanding must be greater than zero")
+	}
+	if discountRate <= inputs.PerpetualGrowthRate {
+		return Turnaround{}, fmt.Errorf(
+			"discount rate must be greater than the perpetual growth rate",
+		)
+	}
+	if inputs.TurnaroundYear < 1 || inputs.TurnaroundYear > numYears {
+		return Turnaround{}, fmt.Errorf(
+			"turnaround year must be within the %d projected years",
+			numYears,
+		)
+	}
+	if inputs.TargetFCF <= 0 {
+		return Turnaround{}, fmt.Errorf("target FCF must be greater than zero")
+	}
+	if inputs.FailureProbability < 0 || inputs.FailureProbability > 1 {
+		return Turnaround{}, fmt.Errorf("probability of failure must be between 0 and 1")
+	}
+
+	s := newSettings(opts)
+
+	var (
+		result    Turnaround
+		newShares float64
+	)
+
+	totalValue := 0.0
+	cash := float64(inputs.Cash)
+	currentFCF := math.Min(float64(inputs.CurrentFCF), 0)
+
+	for i := 1; i <= numYears; i++ {
+		var projectedFCF float64
+		if i < inputs.TurnaroundYear {
+			// the burn narrows until it's gone in the turnaround year
+			remaining := float64(inputs.TurnaroundYear-i) / float64(inputs.TurnaroundYear)
+			projectedFCF = currentFCF * remaining
+		} else {
+			// then FCF ramps up to the target in the final year
+			rampYears := numYears - inputs.TurnaroundYear + 1
+			rampYear := i - inputs.TurnaroundYear + 1
+			projectedFCF = float64(inputs.TargetFCF) * float64(rampYear) / float64(rampYears)
+		}
+
+		result.Projections = append(result.Projections, int(projectedFCF))
+
+		presentValue := s.presentValue(projectedFCF, discountRate, i)
+		totalValue += presentValue
+
+		if projectedFCF >= 0 {
+			continue
+		}
+
+		burn := -projectedFCF
+		result.CashBurn += int(burn)
+
+		// the cash on hand is used first, then the shortfall is raised by issuing shares
+		shortfall := math.Max(burn-cash, 0)
+		cash = math.Max(cash-burn, 0)
+
+		if inputs.IssuePrice > 0 && shortfall > 0 {
+			newShares += shortfall / inputs.IssuePrice
+			totalValue += presentValue * shortfall / projectedFCF
+		}
+	}
+
+	// stable growth phase
+	terminalValue := float64(inputs.TargetFCF) * (1 + inputs.PerpetualGrowthRate) /
+		(discountRate - inputs.PerpetualGrowthRate)
+	pvTerminalValue := s.terminalPresentValue(terminalValue, discountRate, numYears)
+	s.terminal(
+		pvTerminalValue,
+		ImpliedExitMultiple(discountRate, inputs.PerpetualGrowthRate),
+		inputs.PerpetualGrowthRate,
+	)
+
+	totalValue += pvTerminalValue
+
+	result.NewShares = int(math.Round(newShares))
+	result.GoingConcernValue = s.perShare(totalValue, sharesOutstanding+result.NewShares)
+	result.FairValue = (1-inputs.FailureProbability)*result.GoingConcernValue +
+		inputs.FailureProbability*inputs.DistressValue
+
+	return result, nil
+}
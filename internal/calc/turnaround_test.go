@@ -0,0 +1,57 @@
+package calc
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func Test_DCFTurnaround(t *testing.T) {
+	inputs := TurnaroundInputs{
+		CurrentFCF:     -100,
+		TurnaroundYear: 2,
+		TargetFCF:      100,
+	}
+
+	result, err := DCFTurnaround(inputs, 3, 10, 0.1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the burn halves, FCF turns positive in year 2 and reaches the target in year 3
+	if !reflect.DeepEqual(result.Projections, []int{-50, 50, 100}) || result.CashBurn != 50 {
+		fmt.Println(result)
+		t.Fatalf(`DCFTurnaround(%+v, ...) = %+v`, inputs, result)
+	}
+
+	goingConcern := (-50/1.1 + 50/1.21 + 100/1.331 + 1000/1.331) / 10
+	if math.Abs(result.FairValue-goingConcern) > 1e-9 {
+		fmt.Println(result.FairValue)
+		t.Fatalf(`DCFTurnaround(%+v, ...) = %+v, expected %f`, inputs, result, goingConcern)
+	}
+
+	// the burn beyond the cash on hand is funded by issuing shares, and the value is weighted by failure
+	inputs.Cash = 20
+	inputs.IssuePrice = 5
+	inputs.FailureProbability = 0.2
+	inputs.DistressValue = 1
+
+	result, err = DCFTurnaround(inputs, 3, 10, 0.1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	diluted := (goingConcern*10 + 30/1.1) / 16
+	if result.NewShares != 6 ||
+		math.Abs(result.GoingConcernValue-diluted) > 1e-9 ||
+		math.Abs(result.FairValue-(0.8*diluted+0.2)) > 1e-9 {
+		fmt.Println(result)
+		t.Fatalf(`DCFTurnaround(%+v, ...) = %+v`, inputs, result)
+	}
+
+	inputs.TurnaroundYear = 4
+	if _, err := DCFTurnaround(inputs, 3, 10, 0.1); err == nil {
+		t.Fatalf(`DCFTurnaround should reject a turnaround year beyond the projection`)
+	}
+}
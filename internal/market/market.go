@@ -0,0 +1,99 @@
+package market
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+)
+
+// MatureMarketERP is the equity risk premium of a mature market, before any country risk premium.
+const MatureMarketERP = 0.05
+
+// Rates holds the default discount rate inputs for a country.
+type Rates struct {
+	// RiskFreeRate is the yield of the country's 10-year government bond, in its local currency.
+	RiskFreeRate float64 `json:"riskFreeRate"`
+	// CountryRiskPremium is the additional equity risk premium for the country's default and political risk.
+	CountryRiskPremium float64 `json:"countryRiskPremium"`
+}
+
+// EquityRiskPremium returns the mature market equity risk premium plus the country risk premium.
+func (r Rates) EquityRiskPremium() float64 {
+	return MatureMarketERP + r.CountryRiskPremium
+}
+
+// Table maps a country code, as in quickfs.CountryCodes, to its rates.
+type Table map[string]Rates
+
+// DefaultTable holds approximate rates for each supported country, as of early 2024.
+// Risk premiums are based on sovereign ratings (after Damodaran). Override them with Load to keep them current.
+var DefaultTable = Table{
+	"US": {RiskFreeRate: 0.042, CountryRiskPremium: 0},
+	"AT": {RiskFreeRate: 0.030, CountryRiskPremium: 0.0063},
+	"AU": {RiskFreeRate: 0.042, CountryRiskPremium: 0},
+	"BE": {RiskFreeRate: 0.030, CountryRiskPremium: 0.0083},
+	"CA": {RiskFreeRate: 0.034, CountryRiskPremium: 0},
+	"CH": {RiskFreeRate: 0.008, CountryRiskPremium: 0},
+	"DE": {RiskFreeRate: 0.024, CountryRiskPremium: 0},
+	"DK": {RiskFreeRate: 0.025, CountryRiskPremium: 0},
+	"ES": {RiskFreeRate: 0.032, CountryRiskPremium: 0.0156},
+	"FI": {RiskFreeRate: 0.028, CountryRiskPremium: 0.0055},
+	"FR": {RiskFreeRate: 0.029, CountryRiskPremium: 0.0063},
+	"GR": {RiskFreeRate: 0.034, CountryRiskPremium: 0.0286},
+	"IT": {RiskFreeRate: 0.039, CountryRiskPremium: 0.0246},
+	"LN": {RiskFreeRate: 0.041, CountryRiskPremium: 0.0083},
+	"MM": {RiskFreeRate: 0.095, CountryRiskPremium: 0.0286},
+	"NL": {RiskFreeRate: 0.027, CountryRiskPremium: 0},
+	"NO": {RiskFreeRate: 0.035, CountryRiskPremium: 0},
+	"NZ": {RiskFreeRate: 0.046, CountryRiskPremium: 0},
+	"PL": {RiskFreeRate: 0.055, CountryRiskPremium: 0.0131},
+	"SE": {RiskFreeRate: 0.023, CountryRiskPremium: 0},
+}
+
+// Load returns the default table, with any countries in a JSON file replacing the defaults, e.g:
+//
+//	{"PL": {"riskFreeRate": 0.057, "countryRiskPremium": 0.0131}}
+//
+// A missing file is not an error, the default table is returned.
+func Load(path string) (Table, error) {
+	table := make(Table, len(DefaultTable))
+	for code, rates := range DefaultTable {
+		table[code] = rates
+	}
+
+	if path == "" {
+		return table, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return table, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading country rates: %w", err)
+	}
+
+	var overrides Table
+	if err := json.Unmarshal(data, &overrides); err != nil {
+		return nil, fmt.Errorf("error decoding country rates %s: %w", path, err)
+	}
+
+	for code, rates := range overrides {
+		table[strings.ToUpper(code)] = rates
+	}
+
+	return table, nil
+}
+
+// Lookup returns the rates for a country code, falling back to the US rates for unknown countries.
+func (t Table) Lookup(country string) (Rates, bool) {
+	rates, ok := t[strings.ToUpper(country)]
+	if !ok {
+		return t["US"], false
+	}
+
+	return rates, true
+}
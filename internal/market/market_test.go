@@ -0,0 +1,46 @@
+package market
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_Load(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "country_rates.json")
+
+	err := os.WriteFile(path, []byte(`{"pl": {"riskFreeRate": 0.06, "countryRiskPremium": 0.02}}`), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	table, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// overridden countries are replaced, the rest keep their defaults
+	if rates, _ := table.Lookup("PL"); rates.RiskFreeRate != 0.06 || rates.EquityRiskPremium() != 0.07 {
+		t.Fatalf(`Lookup("PL") = %+v`, rates)
+	}
+
+	if rates, _ := table.Lookup("GR"); rates != DefaultTable["GR"] {
+		t.Fatalf(`Lookup("GR") = %+v`, rates)
+	}
+
+	// the overrides don't leak into the default table
+	if DefaultTable["PL"].RiskFreeRate == 0.06 {
+		t.Fatalf(`Load(%s) modified the default table`, path)
+	}
+}
+
+func Test_Load_Missing(t *testing.T) {
+	table, err := Load(filepath.Join(t.TempDir(), "missing.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if rates, ok := table.Lookup("XX"); ok || rates != DefaultTable["US"] {
+		t.Fatalf(`Lookup("XX") = %+v, %t`, rates, ok)
+	}
+}
@@ -2,23 +2,37 @@ package output
 
 import (
 	"fmt"
+	"math"
 	"os"
+	"strings"
 
 	"github.com/olekukonko/tablewriter"
+	"github.com/shanehull/quickval/internal/calc"
 	"github.com/shanehull/quickval/internal/quickfs"
+	"github.com/shanehull/quickval/internal/scenario"
 )
 
+// the width of the largest bar in a histogram
+var histogramWidth = 18
+
 type Writer struct {
-	table *tablewriter.Table
+	file   *os.File
+	table  *tablewriter.Table
+	grids  []*tablewriter.Table
+	titles []string
 }
 
 func NewWriter(file *os.File) *Writer {
-	w := &Writer{table: tablewriter.NewWriter(file)}
-	w.table.SetBorders(tablewriter.Border{Left: false, Top: true, Right: false, Bottom: true})
-	w.table.SetCenterSeparator("|")
-	w.table.SetRowSeparator("=")
+	return &Writer{file: file, table: newTable(file)}
+}
+
+func newTable(file *os.File) *tablewriter.Table {
+	table := tablewriter.NewWriter(file)
+	table.SetBorders(tablewriter.Border{Left: false, Top: true, Right: false, Bottom: true})
+	table.SetCenterSeparator("|")
+	table.SetRowSeparator("=")
 
-	return w
+	return table
 }
 
 func (w *Writer) Data(data *quickfs.Data) {
@@ -26,37 +40,98 @@ func (w *Writer) Data(data *quickfs.Data) {
 	w.table.Append([]string{"FY HISTORIC DATA", ""})
 	w.table.Append([]string{"----------------------------------------", "------------------"})
 
-	// append FCF values
-	for year, value := range data.FCFHistory {
-		label := fmt.Sprintf("FCF Yr %d", year+1)
-		formattedValue := fmt.Sprintf("%d", value)
-		row := []string{label, formattedValue}
-		w.table.Append(row)
+	if data.CashFlow != "" && data.CashFlow != quickfs.CashFlowFCF {
+		w.table.Append([]string{"Cash Flow Basis", data.CashFlow.Label()})
 	}
+	w.appendHistory(data.CashFlow.Label(), data.FCFHistory)
+	w.appendHistory("Cash Paid for Dividends", data.CFFDividends)
+	w.appendHistory("Cash Paid for Repurchases", data.CFFRepurchased)
+	w.appendHistory("Cash from Share Issuance", data.CFFIssued)
+	w.appendHistory("Net Income", data.NetIncome)
+	w.appendHistory("Diluted Shares", data.SharesHistory)
+	w.appendHistory("Book Value", data.BookValue)
+	w.appendHistory("Revenue", data.Revenue)
+	w.appendHistory("EBIT", data.EBIT)
+	w.appendHistory("Invested Capital", data.InvestedCapital)
+	w.appendHistory("D&A", data.Depreciation)
+	w.appendHistory("Interest Expense", data.Interest)
 
-	// append CFFDividends values
-	for year, value := range data.CFFDividends {
-		label := fmt.Sprintf("Cash Paid for Dividends Yr %d", year+1)
-		formattedValue := fmt.Sprintf("%d", value)
+	// append ROE and ROIC values
+	for year, value := range data.ROE {
+		label := fmt.Sprintf("ROE Yr %d", year+1)
+		formattedValue := fmt.Sprintf("%.3f", value)
 		row := []string{label, formattedValue}
 		w.table.Append(row)
 	}
+	for year, value := range data.ROIC {
+		w.table.Append([]string{fmt.Sprintf("ROIC Yr %d", year+1), fmt.Sprintf("%.3f", value)})
+	}
+	for year, value := range data.OperatingMargin {
+		w.table.Append([]string{
+			fmt.Sprintf("Operating Margin Yr %d", year+1),
+			fmt.Sprintf("%.3f", value),
+		})
+	}
 
 	w.table.Append([]string{"", ""})
 }
 
-func (w *Writer) WACC(rate float64, erp float64, rfr float64, data *quickfs.Data) {
+// formatRate formats a rate or multiple, or n/a when it couldn't be solved for or is meaningless (NaN).
+func formatRate(rate float64) string {
+	if math.IsNaN(rate) {
+		return "n/a"
+	}
+	return fmt.Sprintf("%.2f", rate)
+}
+
+// appendHistory appends a row for each year of a historic series.
+func (w *Writer) appendHistory(label string, values []int) {
+	for year, value := range values {
+		w.table.Append([]string{fmt.Sprintf("%s Yr %d", label, year+1), fmt.Sprintf("%d", value)})
+	}
+}
+
+func (w *Writer) WACC(
+	wacc calc.CostOfCapital,
+	erp float64,
+	rfr float64,
+	debtToEquity float64,
+	riskLabel string,
+	costOfDebtSource string,
+	data *quickfs.Data,
+) {
 	w.table.Append([]string{"", ""})
 	w.table.Append([]string{"DISCOUNT RATE (WACC)", ""})
 	w.table.Append([]string{"----------------------------------------", "------------------"})
 	w.table.Append([]string{"Equity Risk Premium", fmt.Sprintf("%.3f", erp)})
 	w.table.Append([]string{"Risk Free Rate", fmt.Sprintf("%.3f", rfr)})
-	if data.DebtToEquity != 0 {
-		w.table.Append([]string{"Debt to Equity Ratio", fmt.Sprintf("%.3f", data.DebtToEquity)})
+	w.table.Append([]string{"Tax Rate", fmt.Sprintf("%.3f", data.TaxRate)})
+	w.table.Append([]string{"Debt to Equity Ratio", fmt.Sprintf("%.3f", debtToEquity)})
+	if data.Beta != 0 {
+		w.table.Append([]string{"Observed Beta", fmt.Sprintf("%.3f", data.Beta)})
 	}
-	if data.TaxRate != 0 {
-		w.table.Append([]string{"Tax Rate", fmt.Sprintf("%.3f", data.TaxRate)})
+	if wacc.UnleveredBeta != 0 {
+		w.table.Append([]string{"Unlevered Beta", fmt.Sprintf("%.3f", wacc.UnleveredBeta)})
 	}
+	w.table.Append([]string{riskLabel, fmt.Sprintf("%.3f", wacc.Beta)})
+	w.table.Append([]string{"Cost of Equity", fmt.Sprintf("%.3f", wacc.CostOfEquity)})
+	w.table.Append([]string{"Cost of Debt Source", costOfDebtSource})
+	w.table.Append([]string{"Pre-Tax Cost of Debt", fmt.Sprintf("%.3f", wacc.CostOfDebt)})
+	w.table.Append([]string{"After-Tax Cost of Debt", fmt.Sprintf("%.3f", wacc.AfterTaxCostOfDebt)})
+	w.table.Append([]string{"Equity Weight", fmt.Sprintf("%.3f", wacc.EquityWeight)})
+	w.table.Append([]string{"Debt Weight", fmt.Sprintf("%.3f", wacc.DebtWeight)})
+	w.table.Append([]string{"", ""})
+	w.table.Append([]string{"Discount Rate", fmt.Sprintf("%.2f", wacc.WACC)})
+
+	w.table.Append([]string{"", ""})
+}
+
+func (w *Writer) CostOfEquity(rate float64, erp float64, rfr float64, data *quickfs.Data) {
+	w.table.Append([]string{"", ""})
+	w.table.Append([]string{"DISCOUNT RATE (COST OF EQUITY)", ""})
+	w.table.Append([]string{"----------------------------------------", "------------------"})
+	w.table.Append([]string{"Equity Risk Premium", fmt.Sprintf("%.3f", erp)})
+	w.table.Append([]string{"Risk Free Rate", fmt.Sprintf("%.3f", rfr)})
 	if data.Beta != 0 {
 		w.table.Append([]string{"Beta", fmt.Sprintf("%.3f", data.Beta)})
 	}
@@ -66,10 +141,51 @@ func (w *Writer) WACC(rate float64, erp float64, rfr float64, data *quickfs.Data
 	w.table.Append([]string{"", ""})
 }
 
+func (w *Writer) ResidualIncome(bookValuePerShare float64, roe float64, costOfEquity float64) {
+	w.table.Append([]string{"", ""})
+	w.table.Append([]string{"RESIDUAL INCOME", ""})
+	w.table.Append([]string{"----------------------------------------", "------------------"})
+	w.table.Append([]string{"Book Value per Share", fmt.Sprintf("%.2f", bookValuePerShare)})
+	w.table.Append([]string{"Return on Equity", fmt.Sprintf("%.3f", roe)})
+	w.table.Append([]string{"Cost of Equity", fmt.Sprintf("%.3f", costOfEquity)})
+	w.table.Append([]string{"Excess Return", fmt.Sprintf("%.3f", roe-costOfEquity)})
+}
+
+func (w *Writer) EarningsPower(
+	ebitMargin float64,
+	normalizedEBIT float64,
+	taxRate float64,
+	depreciation int,
+	maintenanceCapex int,
+	adjustedEarnings float64,
+	epv float64,
+	growthValue float64,
+	price float64,
+) {
+	w.table.Append([]string{"", ""})
+	w.table.Append([]string{"EARNINGS POWER VALUE", ""})
+	w.table.Append([]string{"----------------------------------------", "------------------"})
+	w.table.Append([]string{"Average EBIT Margin", fmt.Sprintf("%.3f", ebitMargin)})
+	w.table.Append([]string{"Normalized EBIT", fmt.Sprintf("%.0f", normalizedEBIT)})
+	w.table.Append([]string{"Tax Rate", fmt.Sprintf("%.3f", taxRate)})
+	w.table.Append([]string{"D&A", fmt.Sprintf("%d", depreciation)})
+	w.table.Append([]string{"Maintenance Capex", fmt.Sprintf("%d", maintenanceCapex)})
+	w.table.Append([]string{"Adjusted Earnings", fmt.Sprintf("%.0f", adjustedEarnings)})
+	w.table.Append([]string{"", ""})
+	w.table.Append([]string{"EPV per Share", fmt.Sprintf("%.2f", epv)})
+	w.table.Append([]string{"Growth DCF per Share (Two-Stage)", fmt.Sprintf("%.2f", growthValue)})
+	w.table.Append([]string{"Value of Growth", fmt.Sprintf("%.2f", growthValue-epv)})
+	w.table.Append([]string{"", ""})
+	w.table.Append([]string{"Current Price", fmt.Sprintf("%.2f", price)})
+	w.table.Append([]string{"Price Paid for Growth", fmt.Sprintf("%.2f", price-epv)})
+	w.table.Append([]string{"", ""})
+}
+
 func (w *Writer) Projected(
 	projected []int,
 	growthRate float64,
 	expectedReturn float64,
+	impliedReturn float64,
 	upside float64,
 ) {
 	w.table.Append([]string{"", ""})
@@ -85,11 +201,263 @@ func (w *Writer) Projected(
 	}
 
 	w.table.Append([]string{"", ""})
-	w.table.Append([]string{"Growth Rate (CAGR)", fmt.Sprintf("%.2f", growthRate)})
+	w.table.Append([]string{"Growth Rate", fmt.Sprintf("%.2f", growthRate)})
 	w.table.Append([]string{"Expected Return (CAGR)", fmt.Sprintf("%.2f", expectedReturn)})
+	w.table.Append([]string{"Implied Return (IRR)", formatRate(impliedReturn)})
+	w.table.Append([]string{"Potential Upside", fmt.Sprintf("%.2f", upside)})
+}
+
+// ShareholderYield appends the historical dividend, buyback and issuance yields on the current market cap.
+func (w *Writer) ShareholderYield(yields calc.ShareholderYield) {
+	w.table.Append([]string{"", ""})
+	w.table.Append([]string{"SHAREHOLDER YIELD", ""})
+	w.table.Append([]string{"----------------------------------------", "------------------"})
+	w.table.Append([]string{"Dividend Yield", fmt.Sprintf("%.4f", yields.DividendYield)})
+	w.table.Append([]string{"Buyback Yield", fmt.Sprintf("%.4f", yields.BuybackYield)})
+	w.table.Append([]string{"Net Issuance Yield", fmt.Sprintf("%.4f", -yields.IssuanceYield)})
+	w.table.Append([]string{"Total Shareholder Yield", fmt.Sprintf("%.4f", yields.TotalYield)})
+}
+
+func (w *Writer) StoryProjected(
+	projections []calc.StoryProjection,
+	inputs calc.StoryInputs,
+	impliedReturn float64,
+	upside float64,
+) {
+	w.table.Append([]string{"", ""})
+	w.table.Append([]string{"PROJECTIONS", ""})
+	w.table.Append([]string{"----------------------------------------", "------------------"})
+
+	for i, p := range projections {
+		year := i + 1
+		w.table.Append([]string{fmt.Sprintf("Revenue Yr %d", year), fmt.Sprintf("%d", p.Revenue)})
+		w.table.Append([]string{fmt.Sprintf("EBIT Yr %d", year), fmt.Sprintf("%d", p.EBIT)})
+		w.table.Append([]string{
+			fmt.Sprintf("Reinvestment Yr %d", year),
+			fmt.Sprintf("%d", p.Reinvestment),
+		})
+		w.table.Append([]string{fmt.Sprintf("FCF Yr %d", year), fmt.Sprintf("%d", p.FCF)})
+	}
+
+	w.table.Append([]string{"", ""})
+	w.table.Append([]string{"Revenue Growth Rate", fmt.Sprintf("%.4f", inputs.RevenueGrowth)})
+	w.table.Append([]string{"Current Operating Margin", fmt.Sprintf("%.4f", inputs.CurrentMargin)})
+	w.table.Append([]string{"Target Operating Margin", fmt.Sprintf("%.4f", inputs.TargetMargin)})
+	w.table.Append([]string{"Sales-to-Capital", fmt.Sprintf("%.2f", inputs.SalesToCapital)})
+	w.table.Append([]string{"Tax Rate", fmt.Sprintf("%.4f", inputs.TaxRate)})
+	w.table.Append([]string{"Implied Return (IRR)", formatRate(impliedReturn)})
 	w.table.Append([]string{"Potential Upside", fmt.Sprintf("%.2f", upside)})
 }
 
+func (w *Writer) Turnaround(
+	result calc.Turnaround,
+	inputs calc.TurnaroundInputs,
+	impliedReturn float64,
+	upside float64,
+) {
+	w.table.Append([]string{"", ""})
+	w.table.Append([]string{"PROJECTIONS", ""})
+	w.table.Append([]string{"----------------------------------------", "------------------"})
+
+	for year, value := range result.Projections {
+		w.table.Append([]string{fmt.Sprintf("Projected Yr %d", year+1), fmt.Sprintf("%d", value)})
+	}
+
+	w.table.Append([]string{"", ""})
+	w.table.Append([]string{"TURNAROUND", ""})
+	w.table.Append([]string{"----------------------------------------", "------------------"})
+	w.table.Append([]string{"Turnaround Year", fmt.Sprintf("%d", inputs.TurnaroundYear)})
+	w.table.Append([]string{"Target FCF", fmt.Sprintf("%d", inputs.TargetFCF)})
+	w.table.Append([]string{"Cash Burn", fmt.Sprintf("%d", result.CashBurn)})
+	if inputs.IssuePrice > 0 {
+		w.table.Append([]string{"Cash on Hand", fmt.Sprintf("%d", inputs.Cash)})
+		w.table.Append([]string{"Issue Price", fmt.Sprintf("%.2f", inputs.IssuePrice)})
+		w.table.Append([]string{"New Shares Issued", fmt.Sprintf("%d", result.NewShares)})
+	}
+	w.table.Append([]string{"Going-Concern Value", fmt.Sprintf("%.2f", result.GoingConcernValue)})
+	w.table.Append([]string{
+		"Probability of Failure",
+		fmt.Sprintf("%.2f", inputs.FailureProbability),
+	})
+	w.table.Append([]string{"Distress Value", fmt.Sprintf("%.2f", inputs.DistressValue)})
+	w.table.Append([]string{"Implied Return (IRR)", formatRate(impliedReturn)})
+	w.table.Append([]string{"Potential Upside", fmt.Sprintf("%.2f", upside)})
+}
+
+func (w *Writer) PayoutProjected(
+	projections []calc.PayoutProjection,
+	inputs calc.PayoutInputs,
+	impliedReturn float64,
+	upside float64,
+) {
+	w.table.Append([]string{"", ""})
+	w.table.Append([]string{"PROJECTIONS", ""})
+	w.table.Append([]string{"----------------------------------------", "------------------"})
+
+	for i, p := range projections {
+		year := i + 1
+		w.table.Append([]string{fmt.Sprintf("Earnings Yr %d", year), fmt.Sprintf("%d", p.Earnings)})
+		w.table.Append([]string{
+			fmt.Sprintf("Payout Ratio Yr %d", year),
+			fmt.Sprintf("%.3f", p.PayoutRatio),
+		})
+		w.table.Append([]string{fmt.Sprintf("Dividends Yr %d", year), fmt.Sprintf("%d", p.Dividends)})
+	}
+
+	w.table.Append([]string{"", ""})
+	w.table.Append([]string{"Earnings Growth Rate", fmt.Sprintf("%.4f", inputs.GrowthRate)})
+	w.table.Append([]string{"Current Payout Ratio", fmt.Sprintf("%.4f", inputs.CurrentPayout)})
+	w.table.Append([]string{"Mature Payout Ratio", fmt.Sprintf("%.4f", inputs.MaturePayout)})
+	w.table.Append([]string{"Implied Return (IRR)", formatRate(impliedReturn)})
+	w.table.Append([]string{"Potential Upside", fmt.Sprintf("%.2f", upside)})
+}
+
+func (w *Writer) ReverseDCF(model string, price float64, historicGrowthRate float64) {
+	w.table.Append([]string{"", ""})
+	w.table.Append([]string{"REVERSE DCF", ""})
+	w.table.Append([]string{"----------------------------------------", "------------------"})
+	w.table.Append([]string{"Model", model})
+	w.table.Append([]string{"Current Price", fmt.Sprintf("%.2f", price)})
+	w.table.Append([]string{"Historic Growth Rate (CAGR)", fmt.Sprintf("%.2f", historicGrowthRate)})
+}
+
+func (w *Writer) Simulation(sim calc.Simulation, price float64, seed int64, bins int) {
+	w.table.Append([]string{"", ""})
+	w.table.Append([]string{"MONTE CARLO SIMULATION", ""})
+	w.table.Append([]string{"----------------------------------------", "------------------"})
+	w.table.Append([]string{"Runs", fmt.Sprintf("%d", len(sim.Values)+sim.Failed)})
+	w.table.Append([]string{"Rejected Runs", fmt.Sprintf("%d", sim.Failed)})
+	w.table.Append([]string{"Seed", fmt.Sprintf("%d", seed)})
+	w.table.Append([]string{"", ""})
+	w.table.Append([]string{"Mean Fair Value", fmt.Sprintf("%.2f", sim.Mean())})
+
+	for _, p := range []float64{0.05, 0.25, 0.50, 0.75, 0.95} {
+		label := fmt.Sprintf("Fair Value P%d", int(p*100))
+		w.table.Append([]string{label, fmt.Sprintf("%.2f", sim.Percentile(p))})
+	}
+
+	w.table.Append([]string{"", ""})
+	w.table.Append([]string{"Current Price", fmt.Sprintf("%.2f", price)})
+	w.table.Append([]string{
+		"Probability Fair Value > Price",
+		fmt.Sprintf("%.2f", sim.ProbabilityAbove(price)),
+	})
+
+	histogram := sim.Histogram(bins)
+
+	largest := 0
+	for _, bin := range histogram {
+		largest = max(largest, bin.Count)
+	}
+
+	w.table.Append([]string{"", ""})
+	w.table.Append([]string{"FAIR VALUE DISTRIBUTION", ""})
+	w.table.Append([]string{"----------------------------------------", "------------------"})
+
+	for _, bin := range histogram {
+		label := fmt.Sprintf("%.2f to %.2f", bin.Lower, bin.Upper)
+		bar := strings.Repeat("#", bin.Count*histogramWidth/max(largest, 1))
+		w.table.Append([]string{label, fmt.Sprintf("%-*s", histogramWidth, bar)})
+	}
+
+	w.table.Append([]string{"", ""})
+}
+
+func (w *Writer) Scenario(
+	s scenario.Scenario,
+	currentFCF int,
+	terminalLabel string,
+	terminal float64,
+	fairValue float64,
+) {
+	w.table.Append([]string{"", ""})
+	w.table.Append([]string{strings.ToUpper(s.Name) + " SCENARIO", ""})
+	w.table.Append([]string{"----------------------------------------", "------------------"})
+	w.table.Append([]string{"Probability", fmt.Sprintf("%.2f", s.Probability)})
+	if s.Margin != 0 {
+		w.table.Append([]string{"FCF Margin", fmt.Sprintf("%.4f", s.Margin)})
+	}
+	w.table.Append([]string{"Current FCF", fmt.Sprintf("%d", currentFCF)})
+	w.table.Append([]string{"Growth Rate", fmt.Sprintf("%.4f", s.GrowthRate)})
+	w.table.Append([]string{terminalLabel, fmt.Sprintf("%.4f", terminal)})
+	w.table.Append([]string{"Fair Value", fmt.Sprintf("%.2f", fairValue)})
+}
+
+func (w *Writer) ProbabilityWeighted(value float64, price float64, upside float64) {
+	w.table.Append([]string{"", ""})
+	w.table.Append([]string{"PROBABILITY-WEIGHTED", ""})
+	w.table.Append([]string{"----------------------------------------", "------------------"})
+	w.table.Append([]string{"Probability-Weighted Value", fmt.Sprintf("%.2f", value)})
+	w.table.Append([]string{"Current Price", fmt.Sprintf("%.2f", price)})
+	w.table.Append([]string{"Potential Upside", fmt.Sprintf("%.2f", upside)})
+}
+
+func (w *Writer) EquityBridge(breakdown calc.Breakdown, bridge calc.EquityBridge, shares int) {
+	w.table.Append([]string{"", ""})
+	w.table.Append([]string{"EQUITY BRIDGE", ""})
+	w.table.Append([]string{"----------------------------------------", "------------------"})
+	w.table.Append([]string{"Enterprise Value", fmt.Sprintf("%.0f", breakdown.EnterpriseValue)})
+	w.table.Append([]string{"Less Net Debt", fmt.Sprintf("%d", bridge.NetDebt)})
+	w.table.Append([]string{"Less Minority Interest", fmt.Sprintf("%d", bridge.MinorityInterest)})
+	w.table.Append([]string{"Less Preferred Equity", fmt.Sprintf("%d", bridge.PreferredEquity)})
+	if breakdown.Dilution != 0 {
+		w.table.Append([]string{"Less Dilution", fmt.Sprintf("%.0f", breakdown.Dilution)})
+	}
+	w.table.Append([]string{"Equity Value", fmt.Sprintf("%.0f", breakdown.EquityValue)})
+	w.table.Append([]string{"Shares Outstanding", fmt.Sprintf("%d", shares)})
+	w.table.Append([]string{"", ""})
+}
+
+func (w *Writer) TerminalValue(breakdown calc.Breakdown, warnings []string) {
+	w.table.Append([]string{"", ""})
+	w.table.Append([]string{"TERMINAL VALUE", ""})
+	w.table.Append([]string{"----------------------------------------", "------------------"})
+	w.table.Append([]string{"PV of Terminal Value", fmt.Sprintf("%.0f", breakdown.TerminalValue)})
+	w.table.Append([]string{"Share of Total Value", formatRate(breakdown.TerminalShare)})
+	w.table.Append([]string{"Exit Multiple", formatRate(breakdown.TerminalMultiple)})
+	w.table.Append([]string{
+		"Perpetual Growth Rate",
+		fmt.Sprintf("%.4f", breakdown.TerminalGrowthRate),
+	})
+
+	for _, warning := range warnings {
+		w.table.Append([]string{"WARNING", warning})
+	}
+}
+
+func (w *Writer) Discounting(midYear bool, stub float64) {
+	convention := "End of Year"
+	if midYear {
+		convention = "Mid-Year"
+	}
+
+	w.table.Append([]string{"", ""})
+	w.table.Append([]string{"DISCOUNTING", ""})
+	w.table.Append([]string{"----------------------------------------", "------------------"})
+	w.table.Append([]string{"Convention", convention})
+	w.table.Append([]string{"First Period (Years)", fmt.Sprintf("%.3f", stub)})
+	w.table.Append([]string{"", ""})
+}
+
+func (w *Writer) DiscountCurve(breakdown calc.Breakdown) {
+	w.table.Append([]string{"", ""})
+	w.table.Append([]string{"DISCOUNT CURVE", ""})
+	w.table.Append([]string{"----------------------------------------", "------------------"})
+
+	for year, rate := range breakdown.DiscountRates {
+		w.table.Append([]string{
+			fmt.Sprintf("Discount Rate Yr %d", year+1),
+			fmt.Sprintf("%.4f", rate),
+		})
+		w.table.Append([]string{
+			fmt.Sprintf("Discount Factor Yr %d", year+1),
+			fmt.Sprintf("%.4f", breakdown.DiscountFactors[year]),
+		})
+	}
+
+	w.table.Append([]string{"", ""})
+}
+
 func (w *Writer) DiscountRate(rate float64) {
 	w.table.Append([]string{"", ""})
 	w.table.Append([]string{"----------------------------------------", "------------------"})
@@ -99,13 +467,102 @@ func (w *Writer) DiscountRate(rate float64) {
 	w.table.Append([]string{"", ""})
 }
 
+// QuickChecks appends the per-share inputs of the quick checks and starts the section for their values.
+func (w *Writer) QuickChecks(
+	eps float64,
+	bookValuePerShare float64,
+	growthRate float64,
+	dividendYield float64,
+	price float64,
+) {
+	w.table.Append([]string{"", ""})
+	w.table.Append([]string{"QUICK CHECKS", ""})
+	w.table.Append([]string{"----------------------------------------", "------------------"})
+	w.table.Append([]string{"EPS", fmt.Sprintf("%.2f", eps)})
+	w.table.Append([]string{"Book Value per Share", fmt.Sprintf("%.2f", bookValuePerShare)})
+	w.table.Append([]string{"Growth Rate", fmt.Sprintf("%.4f", growthRate)})
+	w.table.Append([]string{"Dividend Yield", fmt.Sprintf("%.4f", dividendYield)})
+	w.table.Append([]string{"Current Price", fmt.Sprintf("%.2f", price)})
+	w.table.Append([]string{"", ""})
+}
+
+// QuickCheck appends the value per share of a quick check and its upside. A NaN value could not be calculated,
+// e.g. the Graham number of a company with negative earnings.
+func (w *Writer) QuickCheck(label string, value float64, upside float64) {
+	if math.IsNaN(value) {
+		w.table.Append([]string{label, "n/a"})
+		return
+	}
+
+	w.table.Append([]string{label, fmt.Sprintf("%.2f", value)})
+	w.table.Append([]string{fmt.Sprintf("%s Upside", label), fmt.Sprintf("%.2f", upside)})
+}
+
+// EarningsYield appends the earnings yield against the local bond yield.
+func (w *Writer) EarningsYield(earningsYield float64, bondYield float64) {
+	w.table.Append([]string{"", ""})
+	w.table.Append([]string{"Earnings Yield", fmt.Sprintf("%.4f", earningsYield)})
+	w.table.Append([]string{"Bond Yield", fmt.Sprintf("%.4f", bondYield)})
+	w.table.SetFooter([]string{
+		"Earnings Yield Spread",
+		fmt.Sprintf("%.4f", earningsYield-bondYield),
+	})
+	w.table.SetFooterAlignment(1)
+	w.table.Append([]string{"", ""})
+}
+
 func (w *Writer) FairValue(value float64) {
 	w.table.SetFooter([]string{"Fair Value", fmt.Sprintf("%.2f", value)})
 	w.table.SetFooterAlignment(1)
 	w.table.Append([]string{"", ""})
 }
 
+func (w *Writer) ImpliedGrowthRate(rate float64) {
+	w.table.SetFooter([]string{"Implied Growth Rate", fmt.Sprintf("%.4f", rate)})
+	w.table.SetFooterAlignment(1)
+	w.table.Append([]string{"", ""})
+}
+
+// Sensitivity adds a grid of fair values, rendered below the main table, with rows for one input and columns for another.
+func (w *Writer) Sensitivity(
+	title string,
+	rowLabel, columnLabel string,
+	rows, columns []float64,
+	rowFormat, columnFormat string,
+	values [][]float64,
+) {
+	grid := newTable(w.file)
+
+	header := []string{fmt.Sprintf("%s \\ %s", rowLabel, columnLabel)}
+	for _, column := range columns {
+		header = append(header, fmt.Sprintf(columnFormat, column))
+	}
+	grid.SetHeader(header)
+	grid.SetAutoFormatHeaders(false)
+
+	for i, row := range rows {
+		line := []string{fmt.Sprintf(rowFormat, row)}
+		for _, value := range values[i] {
+			if math.IsNaN(value) {
+				line = append(line, "n/a")
+				continue
+			}
+			line = append(line, fmt.Sprintf("%.2f", value))
+		}
+		grid.Append(line)
+	}
+
+	w.grids = append(w.grids, grid)
+	w.titles = append(w.titles, title)
+}
+
 func (w *Writer) Render() {
 	fmt.Println()
 	w.table.Render()
+
+	for i, grid := range w.grids {
+		fmt.Fprintln(w.file)
+		fmt.Fprintln(w.file, w.titles[i])
+		grid.Render()
+	}
 }
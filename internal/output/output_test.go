@@ -4,6 +4,7 @@ import (
 	"os"
 	"testing"
 
+	"github.com/shanehull/quickval/internal/calc"
 	"github.com/shanehull/quickval/internal/quickfs"
 	"github.com/stretchr/testify/assert"
 )
@@ -27,7 +28,15 @@ func WriterRender_Test(t *testing.T) {
 	}
 
 	w.Data(data)
-	w.WACC(0, 0.05, 0.04, data)
+	w.WACC(
+		calc.CostOfCapital{},
+		0.05,
+		0.04,
+		data.DebtToEquity,
+		"Relevered Beta",
+		"Interest / Total Debt",
+		data,
+	)
 
 	// run the render function
 	w.Render()
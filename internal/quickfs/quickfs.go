@@ -33,22 +33,118 @@ var CountryCodes = []string{
 	"SE",
 }
 
+// CashFlow is the basis of the cash flow history retrieved with WithCashFlow.
+type CashFlow string
+
+const (
+	// CashFlowFCF is free cash flow, as reported.
+	CashFlowFCF CashFlow = "fcf"
+	// CashFlowFCFLessSBC is free cash flow less stock-based compensation.
+	CashFlowFCFLessSBC CashFlow = "fcf-sbc"
+	// CashFlowOwnerEarnings is cash from operations less maintenance capex (approximated by D&A).
+	CashFlowOwnerEarnings CashFlow = "owner-earnings"
+	// CashFlowNetIncome is net income.
+	CashFlowNetIncome CashFlow = "net-income"
+	// CashFlowFCFE is free cash flow to equity, i.e. free cash flow plus net borrowing.
+	CashFlowFCFE CashFlow = "fcfe"
+)
+
+var CashFlows = []CashFlow{
+	CashFlowFCF,
+	CashFlowFCFLessSBC,
+	CashFlowOwnerEarnings,
+	CashFlowNetIncome,
+	CashFlowFCFE,
+}
+
+// cashFlowMetrics maps a cash flow basis to its QuickFS metric, and an optional metric that adjusts it.
+var cashFlowMetrics = map[CashFlow]struct {
+	metric     string
+	adjustment string
+	sign       int
+}{
+	CashFlowFCF:           {metric: "fcf"},
+	CashFlowFCFLessSBC:    {metric: "fcf", adjustment: "cfo_stock_comp", sign: -1},
+	CashFlowOwnerEarnings: {metric: "cf_cfo", adjustment: "cfo_da", sign: -1},
+	CashFlowNetIncome:     {metric: "net_income"},
+	CashFlowFCFE:          {metric: "fcf", adjustment: "cff_debt_net", sign: 1},
+}
+
+// Label returns the display name of a cash flow basis.
+func (c CashFlow) Label() string {
+	switch c {
+	case CashFlowFCFLessSBC:
+		return "FCF - SBC"
+	case CashFlowOwnerEarnings:
+		return "Owner Earnings"
+	case CashFlowNetIncome:
+		return "Net Income"
+	case CashFlowFCFE:
+		return "FCFE"
+	default:
+		return "FCF"
+	}
+}
+
+// ParseCashFlow validates a cash flow basis, e.g. "fcf-sbc".
+func ParseCashFlow(basis string) (CashFlow, error) {
+	for _, c := range CashFlows {
+		if string(c) == strings.ToLower(basis) {
+			return c, nil
+		}
+	}
+
+	return "", fmt.Errorf("unsupported cash flow basis %q, expected one of %v", basis, CashFlows)
+}
+
 type Data struct {
-	Price        float64 `json:"price"`
-	Shares       int     `json:"shares"`
-	TaxRate      float64 `json:"taxRate"`
-	DebtToEquity float64 `json:"debtToEquity"`
-	Beta         float64 `json:"beta"`
-	FCFHistory   []int   `json:"fcfHistory"`
-	CFFDividends []int   `json:"cffDividends"`
+	Price            float64   `json:"price"`
+	Shares           int       `json:"shares"`
+	SharesHistory    []int     `json:"sharesHistory"`
+	TaxRate          float64   `json:"taxRate"`
+	DebtToEquity     float64   `json:"debtToEquity"`
+	Beta             float64   `json:"beta"`
+	FCFHistory       []int     `json:"fcfHistory"`
+	CashFlow         CashFlow  `json:"cashFlow"`
+	CFFDividends     []int     `json:"cffDividends"`
+	CFFRepurchased   []int     `json:"cffRepurchased"`
+	CFFIssued        []int     `json:"cffIssued"`
+	NetIncome        []int     `json:"netIncome"`
+	BookValue        []int     `json:"bookValue"`
+	ROE              []float64 `json:"roe"`
+	ROIC             []float64 `json:"roic"`
+	Revenue          []int     `json:"revenue"`
+	EBIT             []int     `json:"ebit"`
+	OperatingMargin  []float64 `json:"operatingMargin"`
+	InvestedCapital  []int     `json:"investedCapital"`
+	Depreciation     []int     `json:"depreciation"`
+	Interest         []int     `json:"interest"`
+	TotalDebt        int       `json:"totalDebt"`
+	Cash             int       `json:"cash"`
+	MinorityInterest int       `json:"minorityInterest"`
+	PreferredEquity  int       `json:"preferredEquity"`
 }
 
 type Companies []string
 
 type quickFS struct {
 	beta         bool
+	shareHistory bool
 	fcf          bool
+	cashFlow     CashFlow
 	cffDividends bool
+	buybacks     bool
+	netIncome    bool
+	bookValue    bool
+	roe          bool
+	roic         bool
+	revenue      bool
+	ebit         bool
+	opMargin     bool
+	invCapital   bool
+	depreciation bool
+	interest     bool
+	balanceSheet bool
 	fyHistory    int
 	apiKey       string
 	client       *http.Client
@@ -63,8 +159,14 @@ func WithAPIKey(key string) ConfigOption {
 }
 
 func WithFCF() ConfigOption {
+	return WithCashFlow(CashFlowFCF)
+}
+
+// WithCashFlow retrieves the FCF history on a different cash flow basis, e.g. quickfs.WithCashFlow(quickfs.CashFlowFCFLessSBC).
+func WithCashFlow(basis CashFlow) ConfigOption {
 	return func(q *quickFS) {
 		q.fcf = true
+		q.cashFlow = basis
 	}
 }
 
@@ -74,6 +176,90 @@ func WithCFFDividends() ConfigOption {
 	}
 }
 
+// WithBuybacks retrieves the FY history of cash paid to repurchase shares and cash raised by issuing shares.
+func WithBuybacks() ConfigOption {
+	return func(q *quickFS) {
+		q.buybacks = true
+	}
+}
+
+// WithNetIncome retrieves the FY history of net income, e.g. to derive the payout ratio with WithCFFDividends.
+func WithNetIncome() ConfigOption {
+	return func(q *quickFS) {
+		q.netIncome = true
+	}
+}
+
+func WithBookValue() ConfigOption {
+	return func(q *quickFS) {
+		q.bookValue = true
+	}
+}
+
+func WithROE() ConfigOption {
+	return func(q *quickFS) {
+		q.roe = true
+	}
+}
+
+func WithROIC() ConfigOption {
+	return func(q *quickFS) {
+		q.roic = true
+	}
+}
+
+func WithRevenue() ConfigOption {
+	return func(q *quickFS) {
+		q.revenue = true
+	}
+}
+
+func WithEBIT() ConfigOption {
+	return func(q *quickFS) {
+		q.ebit = true
+	}
+}
+
+// WithOperatingMargin retrieves the FY history of the operating (EBIT) margin.
+func WithOperatingMargin() ConfigOption {
+	return func(q *quickFS) {
+		q.opMargin = true
+	}
+}
+
+// WithInvestedCapital retrieves the FY history of invested capital, i.e. the debt and equity funding operations.
+func WithInvestedCapital() ConfigOption {
+	return func(q *quickFS) {
+		q.invCapital = true
+	}
+}
+
+func WithDepreciation() ConfigOption {
+	return func(q *quickFS) {
+		q.depreciation = true
+	}
+}
+
+func WithInterestExpense() ConfigOption {
+	return func(q *quickFS) {
+		q.interest = true
+	}
+}
+
+// WithBalanceSheet retrieves the latest FY total debt, cash, minority interest and preferred equity.
+func WithBalanceSheet() ConfigOption {
+	return func(q *quickFS) {
+		q.balanceSheet = true
+	}
+}
+
+// WithShareHistory retrieves the FY history of diluted shares outstanding, in addition to the latest figure.
+func WithShareHistory() ConfigOption {
+	return func(q *quickFS) {
+		q.shareHistory = true
+	}
+}
+
 func WithBeta() ConfigOption {
 	return func(q *quickFS) {
 		q.beta = true
@@ -101,13 +287,31 @@ func (q *quickFS) GetData(ticker string, country string) (Data, error) {
 	var data Data
 
 	type payloadData struct {
-		Price        string `json:"price"`
-		Shares       string `json:"shares"`
-		TaxRate      string `json:"taxRate"`
-		DebtToEquity string `json:"debtToEquity,omitempty"`
-		Beta         string `json:"beta,omitempty"`
-		FCFHistory   string `json:"fcfHistory,omitempty"`
-		CFFDividends string `json:"cffDividends,omitempty"`
+		Price            string `json:"price"`
+		Shares           string `json:"shares"`
+		SharesHistory    string `json:"sharesHistory,omitempty"`
+		TaxRate          string `json:"taxRate"`
+		DebtToEquity     string `json:"debtToEquity,omitempty"`
+		Beta             string `json:"beta,omitempty"`
+		FCFHistory       string `json:"fcfHistory,omitempty"`
+		FCFAdjustment    string `json:"fcfAdjustment,omitempty"`
+		CFFDividends     string `json:"cffDividends,omitempty"`
+		CFFRepurchased   string `json:"cffRepurchased,omitempty"`
+		CFFIssued        string `json:"cffIssued,omitempty"`
+		NetIncome        string `json:"netIncome,omitempty"`
+		BookValue        string `json:"bookValue,omitempty"`
+		ROE              string `json:"roe,omitempty"`
+		ROIC             string `json:"roic,omitempty"`
+		Revenue          string `json:"revenue,omitempty"`
+		EBIT             string `json:"ebit,omitempty"`
+		OperatingMargin  string `json:"operatingMargin,omitempty"`
+		InvestedCapital  string `json:"investedCapital,omitempty"`
+		Depreciation     string `json:"depreciation,omitempty"`
+		Interest         string `json:"interest,omitempty"`
+		TotalDebt        string `json:"totalDebt,omitempty"`
+		Cash             string `json:"cash,omitempty"`
+		MinorityInterest string `json:"minorityInterest,omitempty"`
+		PreferredEquity  string `json:"preferredEquity,omitempty"`
 	}
 
 	type payload struct {
@@ -124,12 +328,29 @@ func (q *quickFS) GetData(ticker string, country string) (Data, error) {
 	}
 
 	q.formatOptionalQFS(&pl.Data.Beta, ticker, country, q.beta, "beta")
+	q.formatOptionalQFS(
+		&pl.Data.SharesHistory,
+		ticker,
+		country,
+		q.shareHistory,
+		"shares_diluted",
+		fmt.Sprintf("FY-%d:FY", q.fyHistory-1),
+	)
+	cashFlow := cashFlowMetrics[q.cashFlow]
 	q.formatOptionalQFS(
 		&pl.Data.FCFHistory,
 		ticker,
 		country,
 		q.fcf,
-		"fcf",
+		cashFlow.metric,
+		fmt.Sprintf("FY-%d:FY", q.fyHistory-1),
+	)
+	q.formatOptionalQFS(
+		&pl.Data.FCFAdjustment,
+		ticker,
+		country,
+		q.fcf && cashFlow.adjustment != "",
+		cashFlow.adjustment,
 		fmt.Sprintf("FY-%d:FY", q.fyHistory-1),
 	)
 	q.formatOptionalQFS(
@@ -140,6 +361,120 @@ func (q *quickFS) GetData(ticker string, country string) (Data, error) {
 		"cff_dividend_paid",
 		fmt.Sprintf("FY-%d:FY", q.fyHistory-1),
 	)
+	q.formatOptionalQFS(
+		&pl.Data.CFFRepurchased,
+		ticker,
+		country,
+		q.buybacks,
+		"cff_common_stock_repurchased",
+		fmt.Sprintf("FY-%d:FY", q.fyHistory-1),
+	)
+	q.formatOptionalQFS(
+		&pl.Data.CFFIssued,
+		ticker,
+		country,
+		q.buybacks,
+		"cff_common_stock_issued",
+		fmt.Sprintf("FY-%d:FY", q.fyHistory-1),
+	)
+	q.formatOptionalQFS(
+		&pl.Data.NetIncome,
+		ticker,
+		country,
+		q.netIncome,
+		"net_income",
+		fmt.Sprintf("FY-%d:FY", q.fyHistory-1),
+	)
+	q.formatOptionalQFS(
+		&pl.Data.BookValue,
+		ticker,
+		country,
+		q.bookValue,
+		"total_equity",
+		fmt.Sprintf("FY-%d:FY", q.fyHistory-1),
+	)
+	q.formatOptionalQFS(
+		&pl.Data.ROE,
+		ticker,
+		country,
+		q.roe,
+		"roe",
+		fmt.Sprintf("FY-%d:FY", q.fyHistory-1),
+	)
+	q.formatOptionalQFS(
+		&pl.Data.ROIC,
+		ticker,
+		country,
+		q.roic,
+		"roic",
+		fmt.Sprintf("FY-%d:FY", q.fyHistory-1),
+	)
+	q.formatOptionalQFS(
+		&pl.Data.Revenue,
+		ticker,
+		country,
+		q.revenue,
+		"revenue",
+		fmt.Sprintf("FY-%d:FY", q.fyHistory-1),
+	)
+	q.formatOptionalQFS(
+		&pl.Data.EBIT,
+		ticker,
+		country,
+		q.ebit,
+		"operating_income",
+		fmt.Sprintf("FY-%d:FY", q.fyHistory-1),
+	)
+	q.formatOptionalQFS(
+		&pl.Data.OperatingMargin,
+		ticker,
+		country,
+		q.opMargin,
+		"operating_margin",
+		fmt.Sprintf("FY-%d:FY", q.fyHistory-1),
+	)
+	q.formatOptionalQFS(
+		&pl.Data.InvestedCapital,
+		ticker,
+		country,
+		q.invCapital,
+		"invested_capital",
+		fmt.Sprintf("FY-%d:FY", q.fyHistory-1),
+	)
+	q.formatOptionalQFS(
+		&pl.Data.Depreciation,
+		ticker,
+		country,
+		q.depreciation,
+		"cfo_da",
+		fmt.Sprintf("FY-%d:FY", q.fyHistory-1),
+	)
+	q.formatOptionalQFS(
+		&pl.Data.Interest,
+		ticker,
+		country,
+		q.interest,
+		"interest_expense",
+		fmt.Sprintf("FY-%d:FY", q.fyHistory-1),
+	)
+	q.formatOptionalQFS(&pl.Data.TotalDebt, ticker, country, q.balanceSheet, "total_debt", "FY")
+	q.formatOptionalQFS(&pl.Data.Cash, ticker, country, q.balanceSheet, "cash_and_equiv", "FY")
+	q.formatOptionalQFS(
+		&pl.Data.MinorityInterest,
+		ticker,
+		country,
+		q.balanceSheet,
+		"minority_interest",
+		"FY",
+	)
+	q.formatOptionalQFS(
+		&pl.Data.PreferredEquity,
+		ticker,
+		country,
+		q.balanceSheet,
+		"preferred_equity",
+		"FY",
+	)
 
 	jsonPayload, err := json.Marshal(pl)
 	if err != nil {
@@ -174,13 +509,31 @@ func (q *quickFS) GetData(ticker string, country string) (Data, error) {
 
 	type dataResponse struct {
 		Data struct {
-			Price        float64   `json:"price"`
-			Shares       []int     `json:"shares"`
-			TaxRate      []float64 `json:"taxRate"`
-			DebtToEquity []float64 `json:"debtToEquity"`
-			Beta         float64   `json:"beta"`
-			FCFHistory   []int     `json:"fcfHistory"`
-			CFFDividends []int     `json:"cffDividends"`
+			Price            float64   `json:"price"`
+			Shares           []int     `json:"shares"`
+			SharesHistory    []int     `json:"sharesHistory"`
+			TaxRate          []float64 `json:"taxRate"`
+			DebtToEquity     []float64 `json:"debtToEquity"`
+			Beta             float64   `json:"beta"`
+			FCFHistory       []int     `json:"fcfHistory"`
+			FCFAdjustment    []int     `json:"fcfAdjustment"`
+			CFFDividends     []int     `json:"cffDividends"`
+			CFFRepurchased   []int     `json:"cffRepurchased"`
+			CFFIssued        []int     `json:"cffIssued"`
+			NetIncome        []int     `json:"netIncome"`
+			BookValue        []int     `json:"bookValue"`
+			ROE              []float64 `json:"roe"`
+			ROIC             []float64 `json:"roic"`
+			Revenue          []int     `json:"revenue"`
+			EBIT             []int     `json:"ebit"`
+			OperatingMargin  []float64 `json:"operatingMargin"`
+			InvestedCapital  []int     `json:"investedCapital"`
+			Depreciation     []int     `json:"depreciation"`
+			Interest         []int     `json:"interest"`
+			TotalDebt        []int     `json:"totalDebt"`
+			Cash             []int     `json:"cash"`
+			MinorityInterest []int     `json:"minorityInterest"`
+			PreferredEquity  []int     `json:"preferredEquity"`
 		} `json:"data"`
 	}
 
@@ -190,13 +543,56 @@ func (q *quickFS) GetData(ticker string, country string) (Data, error) {
 	}
 
 	data = Data{
-		Price:   dataResp.Data.Price,
-		Shares:  dataResp.Data.Shares[0],
-		TaxRate: dataResp.Data.TaxRate[0],
+		Price:        dataResp.Data.Price,
+		Shares:       dataResp.Data.Shares[0],
+		TaxRate:      dataResp.Data.TaxRate[0],
+		DebtToEquity: latestFloat(dataResp.Data.DebtToEquity),
 	}
 
 	assignOptionalField(q.beta, &data.Beta, dataResp.Data.Beta)
+	assignOptionalField(q.shareHistory, &data.SharesHistory, dataResp.Data.SharesHistory)
 	assignOptionalField(q.fcf, &data.FCFHistory, dataResp.Data.FCFHistory)
+	assignOptionalField(q.fcf, &data.CashFlow, q.cashFlow)
+	assignOptionalField(q.netIncome, &data.NetIncome, dataResp.Data.NetIncome)
+	assignOptionalField(q.bookValue, &data.BookValue, dataResp.Data.BookValue)
+	assignOptionalField(q.roe, &data.ROE, dataResp.Data.ROE)
+	assignOptionalField(q.roic, &data.ROIC, dataResp.Data.ROIC)
+	assignOptionalField(q.revenue, &data.Revenue, dataResp.Data.Revenue)
+	assignOptionalField(q.ebit, &data.EBIT, dataResp.Data.EBIT)
+	assignOptionalField(q.opMargin, &data.OperatingMargin, dataResp.Data.OperatingMargin)
+	assignOptionalField(q.invCapital, &data.InvestedCapital, dataResp.Data.InvestedCapital)
+	assignOptionalField(q.depreciation, &data.Depreciation, dataResp.Data.Depreciation)
+
+	// interest is reported as an expense, we want the absolute amount paid
+	if q.interest {
+		for _, i := range dataResp.Data.Interest {
+			data.Interest = append(data.Interest, absInt(i))
+		}
+	}
+
+	// adjust the cash flow history to the requested basis, e.g. deduct SBC from FCF
+	if q.fcf && cashFlow.adjustment != "" {
+		// a partial series would silently mix adjusted and unadjusted years
+		if len(dataResp.Data.FCFAdjustment) != len(data.FCFHistory) {
+			return data, fmt.Errorf(
+				"%s history has %d years, expected %d to match the cash flow history",
+				cashFlow.adjustment,
+				len(dataResp.Data.FCFAdjustment),
+				len(data.FCFHistory),
+			)
+		}
+
+		for i := range data.FCFHistory {
+			data.FCFHistory[i] += cashFlow.sign * dataResp.Data.FCFAdjustment[i]
+		}
+	}
+
+	if q.balanceSheet {
+		data.TotalDebt = latestInt(dataResp.Data.TotalDebt)
+		data.Cash = latestInt(dataResp.Data.Cash)
+		data.MinorityInterest = latestInt(dataResp.Data.MinorityInterest)
+		data.PreferredEquity = latestInt(dataResp.Data.PreferredEquity)
+	}
 
 	if q.cffDividends {
 		for _, c := range dataResp.Data.CFFDividends {
@@ -204,6 +600,14 @@ func (q *quickFS) GetData(ticker string, country string) (Data, error) {
 		}
 	}
 
+	if q.buybacks {
+		// repurchases are reported as an outflow, issuance as an inflow
+		for _, c := range dataResp.Data.CFFRepurchased {
+			data.CFFRepurchased = append(data.CFFRepurchased, reverseInt(c))
+		}
+		data.CFFIssued = dataResp.Data.CFFIssued
+	}
+
 	return data, nil
 }
 
@@ -289,6 +693,8 @@ func assignOptionalField(condition bool, target interface{}, source interface{})
 			*v = source.([]int)
 		case *[]float64:
 			*v = source.([]float64)
+		case *CashFlow:
+			*v = source.(CashFlow)
 		}
 	}
 }
@@ -308,3 +714,26 @@ func handleErrorInBody(bodyStr, ticker, country string) error {
 func reverseInt(value int) int {
 	return -value
 }
+
+func absInt(value int) int {
+	if value < 0 {
+		return -value
+	}
+	return value
+}
+
+// latestFloat returns the most recent value of a series, or zero if the metric is not reported.
+func latestFloat(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	return values[len(values)-1]
+}
+
+// latestInt returns the most recent value of a series, or zero if the metric is not reported.
+func latestInt(values []int) int {
+	if len(values) == 0 {
+		return 0
+	}
+	return values[len(values)-1]
+}
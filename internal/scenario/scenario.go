@@ -0,0 +1,90 @@
+package scenario
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Scenario is one case of a scenario analysis, e.g. bear, base or bull, with its own inputs and probability.
+type Scenario struct {
+	// Name identifies the scenario in the output.
+	Name string `yaml:"name"`
+	// Probability is the likelihood of the scenario. The probabilities of all scenarios sum to 1.
+	Probability float64 `yaml:"probability"`
+	// GrowthRate is the annual growth rate of the FCF over the projection.
+	GrowthRate float64 `yaml:"growthRate"`
+	// Margin is the FCF margin on the latest revenue, which sets the starting FCF. Zero uses the current FCF instead.
+	Margin float64 `yaml:"margin"`
+	// ExitMultiple is the multiple of the final year's FCF, for the growth-exit model.
+	ExitMultiple float64 `yaml:"exitMultiple"`
+	// PerpetualRate is the growth rate after the projection, for the two-stage model.
+	PerpetualRate float64 `yaml:"perpetualRate"`
+}
+
+// The terminal inputs, one of which each scenario must set, depending on the model valuing it.
+const (
+	// ExitMultiple is the terminal input of the growth-exit model.
+	ExitMultiple = "exitMultiple"
+	// PerpetualRate is the terminal input of the two-stage model.
+	PerpetualRate = "perpetualRate"
+)
+
+// Load reads a list of scenarios from a YAML or JSON file (JSON being a subset of YAML), e.g:
+//
+//   - name: Bear
+//     probability: 0.25
+//     growthRate: 0.02
+//     margin: 0.08
+//     exitMultiple: 10
+//
+// Unnamed scenarios are named by their position in the list. Each scenario must set the terminal input, ExitMultiple or
+// PerpetualRate, as a missing input would silently value the terminal at zero, and a probability between 0 and 1.
+func Load(path string, terminalInput string) ([]Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading scenarios: %w", err)
+	}
+
+	var scenarios []Scenario
+	if err := yaml.Unmarshal(data, &scenarios); err != nil {
+		return nil, fmt.Errorf("error decoding scenarios %s: %w", path, err)
+	}
+
+	if len(scenarios) == 0 {
+		return nil, fmt.Errorf("no scenarios in %s", path)
+	}
+
+	// decode the fields each scenario sets, to tell a missing input from an explicit zero
+	var fields []map[string]interface{}
+	if err := yaml.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("error decoding scenarios %s: %w", path, err)
+	}
+
+	for i := range scenarios {
+		if scenarios[i].Name == "" {
+			scenarios[i].Name = fmt.Sprintf("Scenario %d", i+1)
+		}
+
+		if _, ok := fields[i][terminalInput]; !ok {
+			return nil, fmt.Errorf(
+				"scenario %q in %s has no %s, which the model needs for its terminal value",
+				scenarios[i].Name,
+				path,
+				terminalInput,
+			)
+		}
+
+		if p := scenarios[i].Probability; p < 0 || p > 1 {
+			return nil, fmt.Errorf(
+				"scenario %q in %s has a probability of %g, it must be between 0 and 1",
+				scenarios[i].Name,
+				path,
+				p,
+			)
+		}
+	}
+
+	return scenarios, nil
+}
@@ -0,0 +1,100 @@
+package scenario
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func Test_Load(t *testing.T) {
+	expected := []Scenario{
+		{Name: "Bear", Probability: 0.25, GrowthRate: 0.02, Margin: 0.08, ExitMultiple: 10},
+		{Name: "Scenario 2", Probability: 0.75, GrowthRate: 0.1, ExitMultiple: 15, PerpetualRate: 0.03},
+	}
+
+	files := map[string]string{
+		"scenarios.yaml": `
+- name: Bear
+  probability: 0.25
+  growthRate: 0.02
+  margin: 0.08
+  exitMultiple: 10
+- probability: 0.75
+  growthRate: 0.1
+  exitMultiple: 15
+  perpetualRate: 0.03
+`,
+		"scenarios.json": `[
+  {"name": "Bear", "probability": 0.25, "growthRate": 0.02, "margin": 0.08, "exitMultiple": 10},
+  {"probability": 0.75, "growthRate": 0.1, "exitMultiple": 15, "perpetualRate": 0.03}
+]`,
+	}
+
+	for name, content := range files {
+		path := filepath.Join(t.TempDir(), name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		scenarios, err := Load(path, ExitMultiple)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(scenarios, expected) {
+			t.Fatalf(`Load(%s) = %+v`, name, scenarios)
+		}
+	}
+}
+
+func Test_Load_Empty(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scenarios.yaml")
+	if err := os.WriteFile(path, []byte("[]"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Load(path, ExitMultiple); err == nil {
+		t.Fatalf(`Load(%s) should reject a file without scenarios`, path)
+	}
+}
+
+func Test_Load_Invalid(t *testing.T) {
+	files := map[string]string{
+		// the Bear scenario has no perpetual rate for the two-stage model
+		"missing.yaml": `
+- name: Bear
+  probability: 0.5
+  exitMultiple: 10
+- name: Bull
+  probability: 0.5
+  perpetualRate: 0.03
+`,
+		"probability.yaml": `
+- name: Bear
+  probability: 1.5
+  perpetualRate: 0.02
+`,
+	}
+
+	for name, content := range files {
+		path := filepath.Join(t.TempDir(), name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := Load(path, PerpetualRate); err == nil {
+			t.Fatalf(`Load(%s) should reject the scenarios`, name)
+		}
+	}
+
+	// an explicit zero is a valid input, e.g. no growth after the projection
+	path := filepath.Join(t.TempDir(), "zero.yaml")
+	if err := os.WriteFile(path, []byte("- probability: 1\n  perpetualRate: 0\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Load(path, PerpetualRate); err != nil {
+		t.Fatal(err)
+	}
+}